@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+)
+
+/**
+ * List anomalies are events in a supplied list of events that are not faithful
+ *  to the merklelog, beyond the omission of events:
+ *
+ *  1. duplicated events, whose merklelog_entry.commit.index appears more than once in the list.
+ *  2. reordered events, listed out of log order.
+ *  3. injected events, whose merklelog_entry.commit.index points at a leaf that does not
+ *     hash to the event, i.e. forged additions to the list.
+ */
+
+// ListAnomaly is an event in a list of events that is not faithful to the merklelog.
+type ListAnomaly struct {
+
+	// Position of the event in the list
+	Position int
+
+	// Identity of the event
+	Identity string
+
+	// MMRIndex the event claims to be committed to on the merklelog
+	MMRIndex uint64
+}
+
+// ListAnomalies are the events in a list of events, by the category of anomaly.
+type ListAnomalies struct {
+
+	// DuplicatedEvents are the events whose mmr index appears more than once in the list
+	DuplicatedEvents []ListAnomaly
+
+	// ReorderedEvents are the events listed out of log order
+	ReorderedEvents []ListAnomaly
+
+	// InjectedEvents are the events whose mmr index points at a leaf that does not hash to the event
+	InjectedEvents []ListAnomaly
+}
+
+// Found is true if any anomaly is found in the list of events
+func (la ListAnomalies) Found() bool {
+	return len(la.DuplicatedEvents) > 0 || len(la.ReorderedEvents) > 0 || len(la.InjectedEvents) > 0
+}
+
+// ListAnomaliesDemo finds the duplicated, reordered and injected events in the given list of events
+func ListAnomaliesDemo(ctx context.Context, eventsJson []byte) (*ListAnomalies, error) {
+
+	entries, err := events.NewEventEntries(eventsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// now verify each event hashes to the leaf at its mmr index
+	included, err := events.VerifyEventEntries(ctx, reader, massifHeight, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindListAnomalies(entries, included), nil
+}
+
+// FindListAnomalies finds the duplicated, reordered and injected events in the given list of events,
+//
+//	given whether each event is included on the merklelog, by its position in the list.
+func FindListAnomalies(entries []events.EventEntry, included []bool) *ListAnomalies {
+
+	listAnomalies := &ListAnomalies{
+		DuplicatedEvents: DuplicatedEvents(entries),
+		ReorderedEvents:  ReorderedEvents(entries),
+	}
+
+	for position, entry := range entries {
+		if !included[position] {
+			listAnomalies.InjectedEvents = append(listAnomalies.InjectedEvents, newListAnomaly(position, entry))
+		}
+	}
+
+	return listAnomalies
+}
+
+// DuplicatedEvents finds every event in the list whose mmr index appears more than once in the list
+func DuplicatedEvents(entries []events.EventEntry) []ListAnomaly {
+
+	counts := map[uint64]int{}
+	for _, entry := range entries {
+		counts[entry.MMRIndex()]++
+	}
+
+	duplicated := []ListAnomaly{}
+	for position, entry := range entries {
+		if counts[entry.MMRIndex()] > 1 {
+			duplicated = append(duplicated, newListAnomaly(position, entry))
+		}
+	}
+
+	return duplicated
+}
+
+// ReorderedEvents finds the fewest events in the list that are out of log order.
+//
+// The datatrails events API lists events newest first, so a list in descending mmr index order
+// is in log order, as is a list in ascending mmr index order. The order the list follows is the
+// order with the longest run of events in order, the events outside that run are reordered.
+//
+// Duplicated events are not reordered, as they share the same mmr index.
+func ReorderedEvents(entries []events.EventEntry) []ListAnomaly {
+
+	ascending := inOrder(entries, func(a, b uint64) bool { return a <= b })
+	descending := inOrder(entries, func(a, b uint64) bool { return a >= b })
+
+	ordered := descending
+	if countTrue(ascending) > countTrue(descending) {
+		ordered = ascending
+	}
+
+	reordered := []ListAnomaly{}
+	for position, entry := range entries {
+		if !ordered[position] {
+			reordered = append(reordered, newListAnomaly(position, entry))
+		}
+	}
+
+	return reordered
+}
+
+// inOrder finds the longest subsequence of the events that follows the given order,
+//
+//	returning true for the position of each event in that subsequence.
+func inOrder(entries []events.EventEntry, ordered func(a, b uint64) bool) []bool {
+
+	// tails[l] is the position of the last event of the best subsequence of length l+1,
+	//  previous[p] is the position of the event before position p in its subsequence.
+	tails := []int{}
+	previous := make([]int, len(entries))
+
+	for position, entry := range entries {
+
+		// find the longest subsequence this event can follow
+		length := sort.Search(len(tails), func(l int) bool {
+			return !ordered(entries[tails[l]].MMRIndex(), entry.MMRIndex())
+		})
+
+		previous[position] = -1
+		if length > 0 {
+			previous[position] = tails[length-1]
+		}
+
+		if length == len(tails) {
+			tails = append(tails, position)
+			continue
+		}
+
+		tails[length] = position
+	}
+
+	inSubsequence := make([]bool, len(entries))
+	if len(tails) == 0 {
+		return inSubsequence
+	}
+
+	for position := tails[len(tails)-1]; position >= 0; position = previous[position] {
+		inSubsequence[position] = true
+	}
+
+	return inSubsequence
+}
+
+// countTrue counts the true values
+func countTrue(values []bool) int {
+
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+
+	return count
+}
+
+// newListAnomaly creates a list anomaly for the event at the given position in the list
+func newListAnomaly(position int, entry events.EventEntry) ListAnomaly {
+	return ListAnomaly{
+		Position: position,
+		Identity: entry.Identity,
+		MMRIndex: entry.MMRIndex(),
+	}
+}
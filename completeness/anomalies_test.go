@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// entriesAt creates event entries committed at the given mmr indexes
+func entriesAt(mmrIndices ...uint64) []events.EventEntry {
+
+	entries := make([]events.EventEntry, len(mmrIndices))
+	for i, mmrIndex := range mmrIndices {
+		entries[i].MerklelogEntry.Commit.Index = mmrIndex
+	}
+
+	return entries
+}
+
+// anomalyPositions gets the position in the list of each anomaly
+func anomalyPositions(anomalies []ListAnomaly) []int {
+
+	positions := []int{}
+	for _, anomaly := range anomalies {
+		positions = append(positions, anomaly.Position)
+	}
+
+	return positions
+}
+
+// TestDuplicatedEvents tests every event sharing an mmr index is duplicated
+func TestDuplicatedEvents(t *testing.T) {
+
+	assert.Equal(t, []int{}, anomalyPositions(DuplicatedEvents(entriesAt(11, 10, 8, 7))))
+	assert.Equal(t, []int{0, 2}, anomalyPositions(DuplicatedEvents(entriesAt(11, 10, 11, 7))))
+}
+
+// TestReorderedEvents tests the fewest events out of log order are reordered
+func TestReorderedEvents(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		mmrIndices []uint64
+		expected   []int
+	}{
+		{name: "empty", mmrIndices: nil, expected: []int{}},
+		{name: "descending", mmrIndices: []uint64{11, 10, 8, 7}, expected: []int{}},
+		{name: "ascending", mmrIndices: []uint64{7, 8, 10, 11}, expected: []int{}},
+		{name: "duplicated", mmrIndices: []uint64{11, 10, 10, 7}, expected: []int{}},
+		{name: "misplaced", mmrIndices: []uint64{11, 10, 4, 8, 7}, expected: []int{2}},
+		{name: "moved to end", mmrIndices: []uint64{10, 8, 7, 4, 11}, expected: []int{4}},
+		{name: "moved to start", mmrIndices: []uint64{1, 3, 4, 7, 0}, expected: []int{4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, anomalyPositions(ReorderedEvents(entriesAt(test.mmrIndices...))))
+		})
+	}
+}
+
+// TestListAnomaliesDemo tests the sample event list has no anomalies,
+//
+//	and that an event with forged attributes is injected.
+func TestListAnomaliesDemo(t *testing.T) {
+
+	listAnomalies, err := ListAnomaliesDemo(context.Background(), []byte(eventList))
+	require.NoError(t, err)
+
+	assert.Equal(t, false, listAnomalies.Found())
+
+	// forge the attributes of the first event, so it no longer hashes to its leaf,
+	//  and list it twice, out of order.
+	events := sampleEvents(t)
+	forged := strings.Replace(events[0], "Model is mispredicting", "Model is predicting", 1)
+
+	forgedEvents := append([]string{}, events...)
+	forgedEvents[0] = forged
+	forgedEvents = append(forgedEvents, events[0])
+
+	rawEvents := make([]json.RawMessage, len(forgedEvents))
+	for i, event := range forgedEvents {
+		rawEvents[i] = json.RawMessage(event)
+	}
+
+	forgedEventList, err := json.Marshal(map[string]any{"events": rawEvents})
+	require.NoError(t, err)
+
+	listAnomalies, err = ListAnomaliesDemo(context.Background(), forgedEventList)
+	require.NoError(t, err)
+
+	last := len(forgedEvents) - 1
+	assert.Equal(t, []int{0, last}, anomalyPositions(listAnomalies.DuplicatedEvents))
+	assert.Equal(t, []int{last}, anomalyPositions(listAnomalies.ReorderedEvents))
+	assert.Equal(t, []int{0}, anomalyPositions(listAnomalies.InjectedEvents))
+}
+
+// TestFindListAnomalies_Included tests the events not included on the merklelog are injected,
+//
+//	and that the omissions of the list are found from the same verdicts.
+func TestFindListAnomalies_Included(t *testing.T) {
+
+	entries := entriesAt(0, 3, 7)
+
+	listAnomalies := FindListAnomalies(entries, []bool{true, false, true})
+	assert.Equal(t, []int{1}, anomalyPositions(listAnomalies.InjectedEvents))
+
+	_, err := OmittedEvents(entries, []bool{true, false, true})
+	assert.ErrorIs(t, err, events.ErrEventNotIncluded)
+
+	listAnomalies = FindListAnomalies(entries, []bool{true, true, true})
+	assert.Equal(t, false, listAnomalies.Found())
+
+	omittedEvents, err := OmittedEvents(entries, []bool{true, true, true})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []uint64{1, 4}, omittedEvents)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+)
+
+/**
+ * Asset completeness verifies the entire history of a single asset.
+ *
+ * Every event of the asset is gathered from the datatrails events API, and
+ *  each is verified to be included on the merklelog.
+ *
+ * Then every leaf on the merklelog between the first and the last event of the asset,
+ *  that is not an event of the asset, is attributed to the event committed at that leaf.
+ *
+ * Leaves belonging to other assets are benign omissions, as the merklelog is shared by
+ *  many assets. Leaves belonging to the asset are events missing from its history.
+ */
+
+// AssetCompleteness is the result of verifying the entire history of an asset.
+type AssetCompleteness struct {
+
+	// AssetIdentity is the identity of the asset verified
+	AssetIdentity string
+
+	// Events are the identities of the events of the asset, sorted by mmr index
+	Events []string
+
+	// UnverifiedEvents are the identities of the events of the asset NOT included on the merklelog
+	UnverifiedEvents []string
+
+	// OtherAssetLeaves are the mmr indexes of the omitted leaves belonging to other assets
+	OtherAssetLeaves []uint64
+
+	// MissingEvents are the mmr indexes of the omitted leaves belonging to the asset,
+	//  i.e. events of the asset missing from its history.
+	MissingEvents []uint64
+
+	// UnattributedLeaves are the mmr indexes of the omitted leaves that could not be
+	//  attributed to any event, so could belong to the asset.
+	UnattributedLeaves []uint64
+}
+
+// Complete is true if every event of the asset is included on the merklelog, and
+//
+//	every omitted leaf is shown to belong to another asset.
+func (ac AssetCompleteness) Complete() bool {
+	return len(ac.UnverifiedEvents) == 0 && len(ac.MissingEvents) == 0 && len(ac.UnattributedLeaves) == 0
+}
+
+// AssetCompletenessDemo verifies the entire history of the asset with the given identity
+func AssetCompletenessDemo(ctx context.Context, eventsAPI *events.EventsAPI, assetIdentity string) (*AssetCompleteness, error) {
+
+	// first get every event of the asset, across all pages
+	eventsJson, err := eventsAPI.AssetEvents(ctx, assetIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := events.NewEventEntries(eventsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	events.SortByMMRIndex(entries)
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	assetCompleteness := &AssetCompleteness{
+		AssetIdentity: assetIdentity,
+	}
+
+	// now verify each event of the asset is in the merklelog
+	mmrIndices := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+
+		assetCompleteness.Events = append(assetCompleteness.Events, entry.Identity)
+		mmrIndices = append(mmrIndices, entry.MMRIndex())
+
+		verified, err := events.VerifyEventEntry(ctx, reader, massifHeight, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if !verified {
+			assetCompleteness.UnverifiedEvents = append(assetCompleteness.UnverifiedEvents, entry.Identity)
+		}
+	}
+
+	// now find the leaves between the first and last event that are not events of the asset,
+	//  and find which asset each of those leaves belongs to.
+	omittedLeaves := events.OmittedLeaves(mmrIndices)
+	if len(omittedLeaves) == 0 {
+		return assetCompleteness, nil
+	}
+
+	leafEvents, err := AttributeLeaves(ctx, eventsAPI, reader, massifHeight, strings.HasPrefix(assetIdentity, "publicassets/"), omittedLeaves)
+	if err != nil {
+		return nil, err
+	}
+
+	assetCompleteness.OtherAssetLeaves, assetCompleteness.MissingEvents, assetCompleteness.UnattributedLeaves =
+		ClassifyOmittedLeaves(assetIdentity, omittedLeaves, leafEvents)
+
+	return assetCompleteness, nil
+}
+
+// AttributeLeaves finds the event committed at each of the given leaves.
+//
+// The events of all public assets, or all assets of the tenant, are paged through until every leaf
+// is attributed. An event is only attributed to a leaf if the event is verified to be included on
+// the merklelog at that leaf.
+//
+// The events of each page are sorted by mmr index, newest first. Paging stops early, once the events
+// are older than the first leaf, only while the events API is seen to list the events newest first,
+// otherwise every event is paged through.
+//
+// The merklelog is of the given massif height.
+//
+// Returns the event of each attributed leaf, keyed by mmr index.
+func AttributeLeaves(ctx context.Context, eventsAPI *events.EventsAPI, reader azblob.Reader, massifHeight uint8, public bool, mmrIndices []uint64) (map[uint64]events.EventEntry, error) {
+
+	wildcardIdentity := events.WildcardAssetIdentity
+	if public {
+		wildcardIdentity = events.PublicWildcardAssetIdentity
+	}
+
+	unattributed := make(map[uint64]bool, len(mmrIndices))
+	first := mmrIndices[0]
+	for _, mmrIndex := range mmrIndices {
+		unattributed[mmrIndex] = true
+		first = min(first, mmrIndex)
+	}
+
+	leafEvents := map[uint64]events.EventEntry{}
+
+	// the oldest mmr index of the events paged through so far, whether every event paged
+	//  through was older than, or as old as, those before it, and whether any was at or
+	//  after the first leaf.
+	oldest := uint64(math.MaxUint64)
+	newestFirst := true
+	reachedFirst := false
+
+	err := eventsAPI.ListEvents(ctx, wildcardIdentity, func(page []json.RawMessage) (bool, error) {
+
+		entries := make([]events.EventEntry, 0, len(page))
+		for _, eventJson := range page {
+
+			entry, err := events.NewEventEntry(eventJson)
+			if err != nil {
+				return false, err
+			}
+
+			entries = append(entries, entry)
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].MMRIndex() > entries[j].MMRIndex()
+		})
+
+		for _, entry := range entries {
+
+			if entry.MMRIndex() > oldest {
+				newestFirst = false
+			}
+			oldest = min(oldest, entry.MMRIndex())
+			reachedFirst = reachedFirst || entry.MMRIndex() >= first
+
+			if !unattributed[entry.MMRIndex()] {
+				continue
+			}
+
+			verified, err := events.VerifyEventEntry(ctx, reader, massifHeight, entry)
+			if err != nil {
+				return false, err
+			}
+
+			if !verified {
+				continue
+			}
+
+			leafEvents[entry.MMRIndex()] = entry
+			delete(unattributed, entry.MMRIndex())
+		}
+
+		// keep paging while there are unattributed leaves, unless the events are listed
+		//  newest first and have been paged through past the first leaf.
+		older := newestFirst && reachedFirst && oldest < first
+		return len(unattributed) > 0 && !older, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return leafEvents, nil
+}
+
+// ClassifyOmittedLeaves classifies each omitted leaf, using the asset identity of the event
+//
+//	committed at the leaf, as belonging to another asset, belonging to the given asset,
+//	or unattributed.
+func ClassifyOmittedLeaves(assetIdentity string, omittedLeaves []uint64, leafEvents map[uint64]events.EventEntry) (otherAsset []uint64, sameAsset []uint64, unattributed []uint64) {
+
+	for _, mmrIndex := range omittedLeaves {
+
+		leafEvent, attributed := leafEvents[mmrIndex]
+
+		switch {
+		case !attributed:
+			unattributed = append(unattributed, mmrIndex)
+		case leafEvent.AssetIdentity == assetIdentity:
+			sameAsset = append(sameAsset, mmrIndex)
+		default:
+			otherAsset = append(otherAsset, mmrIndex)
+		}
+	}
+
+	return otherAsset, sameAsset, unattributed
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClassifyOmittedLeaves tests omitted leaves are classified by the asset they belong to
+func TestClassifyOmittedLeaves(t *testing.T) {
+
+	assetIdentity := "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6"
+
+	leafEvents := map[uint64]events.EventEntry{
+		3:  {AssetIdentity: "publicassets/fe022486-3272-4d44-aab5-765a37c17b85"},
+		7:  {AssetIdentity: assetIdentity},
+		10: {AssetIdentity: "publicassets/fe022486-3272-4d44-aab5-765a37c17b85"},
+	}
+
+	otherAsset, sameAsset, unattributed := ClassifyOmittedLeaves(assetIdentity, []uint64{3, 4, 7, 10}, leafEvents)
+
+	assert.Equal(t, []uint64{3, 10}, otherAsset)
+	assert.Equal(t, []uint64{7}, sameAsset)
+	assert.Equal(t, []uint64{4}, unattributed)
+}
+
+// TestAssetCompletenessDemo tests every event of the sample asset is included on the merklelog,
+//
+//	and that no leaves are omitted from the history of the asset.
+func TestAssetCompletenessDemo(t *testing.T) {
+
+	eventsURL := eventstest.NewMockEventsAPI(t, 5, sampleEvents(t)...)
+
+	assetCompleteness, err := AssetCompletenessDemo(context.Background(), events.NewEventsAPI(eventsURL), "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6")
+	require.NoError(t, err)
+
+	assert.Equal(t, 13, len(assetCompleteness.Events))
+	assert.Equal(t, true, assetCompleteness.Complete())
+	assert.Equal(t, 0, len(assetCompleteness.OtherAssetLeaves))
+}
+
+// pageCounter counts the requests made to the events API
+type pageCounter struct {
+	pages int
+}
+
+// RoundTrip counts the request, then makes it with the default transport
+func (pc *pageCounter) RoundTrip(r *http.Request) (*http.Response, error) {
+	pc.pages++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// TestAttributeLeaves_Order tests paging stops once past the first leaf only if the
+//
+//	events API lists the events newest first, otherwise every event is paged through.
+func TestAttributeLeaves_Order(t *testing.T) {
+
+	mmrIndices := []uint64{0, 1, 3, 4, 8, 11, 12, 15}
+
+	eventsJson := make([]string, len(mmrIndices))
+	for i, mmrIndex := range mmrIndices {
+		eventsJson[i] = fmt.Sprintf(`{"identity": "publicassets/00000000-0000-0000-0000-000000000000/events/00000000-0000-0000-0000-%012d", "asset_identity": "publicassets/00000000-0000-0000-0000-000000000000", "merklelog_entry": {"commit": {"index": "%d"}}}`, mmrIndex, mmrIndex)
+	}
+
+	newestFirst := make([]string, len(eventsJson))
+	for i, eventJson := range eventsJson {
+		newestFirst[len(eventsJson)-1-i] = eventJson
+	}
+
+	tests := []struct {
+		name   string
+		events []string
+		pages  int
+	}{
+		{"newest first", newestFirst, 2},
+		{"oldest first", eventsJson, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			pageCounter := &pageCounter{}
+			eventsAPI := events.NewEventsAPI(eventstest.NewMockEventsAPI(t, 2, test.events...), events.WithHTTPClient(&http.Client{Transport: pageCounter}))
+
+			// the leaf at mmr index 10 has no event, so can never be attributed
+			leafEvents, err := AttributeLeaves(context.Background(), eventsAPI, nil, 14, true, []uint64{10})
+			require.NoError(t, err)
+
+			assert.Equal(t, map[uint64]events.EventEntry{}, leafEvents)
+			assert.Equal(t, test.pages, pageCounter.pages)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfirmationsDemo tests the confirmed root of each sample event
+//
+//	matches the root recomputed from the merklelog.
+func TestConfirmationsDemo(t *testing.T) {
+
+	confirmations, err := merklelog.VerifyEventConfirmations(context.Background(), []byte(eventList))
+	require.NoError(t, err)
+
+	assert.Equal(t, 13, len(confirmations))
+	for _, confirmation := range confirmations {
+		assert.Equal(t, merklelog.ConfirmVerified, confirmation.Status, confirmation.Identity)
+
+		// the sample events have no signed tree heads
+		assert.Equal(t, merklelog.TreeHeadAbsent, confirmation.SignedTreeHead, confirmation.Identity)
+		assert.Equal(t, merklelog.TreeHeadAbsent, confirmation.Unequivocal, confirmation.Identity)
+	}
+}
@@ -763,9 +763,18 @@ const (
 		"next_page_token": ""
 	}
 	`
-	publicTenantID = "tenant/6ea5cd00-c711-3649-6914-7b125928bbb4"
+)
+
+const (
+	// listMode verifies the given list of events is complete
+	listMode = "list"
+
+	// assetMode verifies the entire history of an asset is complete
+	assetMode = "asset"
+
+	// windowMode verifies the given list of events covers exactly the leaves within a time window
+	windowMode = "window"
 
-	// merklelog reader configuration
-	container = "merklelogs"
-	url       = "https://app.datatrails.ai/verifiabledata"
+	// policyMode verifies the given list of events omits only the leaves permitted by an omission policy
+	policyMode = "policy"
 )
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleEvents splits the sample event list into the json of each event
+func sampleEvents(t *testing.T) []string {
+
+	sampleEventList := struct {
+		Events []json.RawMessage `json:"events"`
+	}{}
+
+	err := json.Unmarshal([]byte(eventList), &sampleEventList)
+	require.NoError(t, err)
+
+	events := make([]string, len(sampleEventList.Events))
+	for i, event := range sampleEventList.Events {
+		events[i] = string(event)
+	}
+
+	return events
+}
+
+// TestEventsFromAPI tests the list of events can be got from the events API,
+//
+//	either by the asset identity or by each event identity.
+func TestEventsFromAPI(t *testing.T) {
+
+	eventsURL := eventstest.NewMockEventsAPI(t, 5, sampleEvents(t)...)
+	eventsAPI := events.NewEventsAPI(eventsURL)
+
+	type eventListIdentities struct {
+		Events []struct {
+			Identity string `json:"identity"`
+		} `json:"events"`
+	}
+
+	expected := eventListIdentities{}
+	err := json.Unmarshal([]byte(eventList), &expected)
+	require.NoError(t, err)
+
+	// all events of the asset
+	eventsJson, err := EventsFromAPI(context.Background(), eventsAPI, "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6", nil)
+	require.NoError(t, err)
+
+	actual := eventListIdentities{}
+	err = json.Unmarshal(eventsJson, &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, actual)
+
+	// each event by identity
+	eventsJson, err = EventsFromAPI(context.Background(), eventsAPI, "", []string{
+		expected.Events[0].Identity,
+		expected.Events[1].Identity,
+	})
+	require.NoError(t, err)
+
+	actual = eventListIdentities{}
+	err = json.Unmarshal(eventsJson, &actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.Events[:2], actual.Events)
+
+	// non public events require a bearer token
+	_, err = EventsFromAPI(context.Background(), eventsAPI, "assets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6", nil)
+	assert.ErrorIs(t, err, events.ErrNoBearerToken)
+}
+
+// TestCompletenessDemo_EventsAPI tests the list of events of an asset got from the events API
+//
+//	is complete and included on the merklelog.
+func TestCompletenessDemo_EventsAPI(t *testing.T) {
+
+	eventsURL := eventstest.NewMockEventsAPI(t, 5, sampleEvents(t)...)
+
+	eventsJson, err := EventsFromAPI(context.Background(), events.NewEventsAPI(eventsURL), "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6", nil)
+	require.NoError(t, err)
+
+	omittedEvents, err := CompletenessDemo(context.Background(), eventsJson)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(omittedEvents))
+
+}
@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+	"time"
 
-	"github.com/datatrails/go-datatrails-common/azblob"
-	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
 )
 
 /**
@@ -17,32 +28,260 @@ import (
  * This proves that the datatrails event is included in the merklelog.
  */
 
+var (
+	ErrUnknownMode = errors.New("unknown completeness mode, expected list, asset, window or policy")
+)
+
 // CompletenessDemo of a list of public datatrails events
-func CompletenessDemo(eventsJson []byte) (omittedEvents []uint64, err error) {
+func CompletenessDemo(ctx context.Context, eventsJson []byte) (omittedEvents []uint64, err error) {
+
+	entries, err := events.NewEventEntries(eventsJson)
+	if err != nil {
+		return nil, err
+	}
 
 	// then create the merklelog reader
-	reader, err := azblob.NewReaderNoAuth(url, azblob.WithContainer(container))
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
 	if err != nil {
 		return nil, err
 	}
 
-	verifiableEvents, err := logverification.NewVerifiableEvents(eventsJson)
+	// now verify each public event is in the merklelog, using the massif height of the merklelog
+	included, err := events.VerifyEventEntries(ctx, reader, massifHeight, entries)
 	if err != nil {
 		return nil, err
 	}
 
-	// now verify the public event is in the merklelog
-	return logverification.VerifyList(reader, verifiableEvents, logverification.WithTenantId(publicTenantID))
+	return OmittedEvents(entries, included)
 
 }
 
-// Demo of the completeness of a public datatrails event
-func main() {
+// OmittedEvents finds the leaves between the first and last event of the given list of events
+//
+//	omitted from the list, given whether each event is included on the merklelog, by its
+//	position in the list.
+//
+// Every event must be included on the merklelog.
+func OmittedEvents(entries []events.EventEntry, included []bool) ([]uint64, error) {
+
+	mmrIndices := make([]uint64, 0, len(entries))
+	for position, entry := range entries {
+
+		if !included[position] {
+			return nil, fmt.Errorf("%w: %s", events.ErrEventNotIncluded, entry.Identity)
+		}
+
+		mmrIndices = append(mmrIndices, entry.MMRIndex())
+	}
 
-	omittedEvents, err := CompletenessDemo([]byte(eventList))
+	// finally find the leaves between the first and last event omitted from the list
+	return events.OmittedLeaves(mmrIndices), nil
+}
+
+// EventsFromAPI gets the list of events to verify from the datatrails events API,
+//
+//	either every event of the given asset, or each of the given events.
+func EventsFromAPI(ctx context.Context, eventsAPI *events.EventsAPI, assetIdentity string, eventIdentities []string) ([]byte, error) {
+
+	if assetIdentity != "" {
+		return eventsAPI.AssetEvents(ctx, assetIdentity)
+	}
+
+	eventList := struct {
+		Events []json.RawMessage `json:"events"`
+	}{
+		Events: make([]json.RawMessage, 0, len(eventIdentities)),
+	}
+
+	for _, eventIdentity := range eventIdentities {
+
+		eventJson, err := eventsAPI.Event(ctx, eventIdentity)
+		if err != nil {
+			return nil, err
+		}
+
+		eventList.Events = append(eventList.Events, eventJson)
+	}
+
+	return json.Marshal(eventList)
+}
+
+// assetDemo of the completeness of the entire history of a datatrails asset
+func assetDemo(ctx context.Context, eventsURL string, assetIdentity string) {
+
+	eventsAPI := events.NewEventsAPI(eventsURL, events.WithBearerToken(os.Getenv(events.BearerTokenEnv)))
+
+	assetCompleteness, err := AssetCompletenessDemo(ctx, eventsAPI, assetIdentity)
 	if err != nil {
-		fmt.Printf("\nFailed Complete List verification: %v\n", err)
-		os.Exit(1)
+		slog.Error("failed asset history verification", logging.KeyAssetIdentity, assetIdentity, logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	slog.Info("asset events", logging.KeyAssetIdentity, assetCompleteness.AssetIdentity, "events", len(assetCompleteness.Events))
+
+	// omissions of other assets are expected, as the merklelog is shared by many assets
+	if len(assetCompleteness.OtherAssetLeaves) > 0 {
+		slog.Info("omitted leaves of other assets", logging.KeyAssetIdentity, assetCompleteness.AssetIdentity, logging.KeyMMRIndices, assetCompleteness.OtherAssetLeaves)
+	}
+
+	if !assetCompleteness.Complete() {
+		slog.Error("failed asset history verification",
+			logging.KeyAssetIdentity, assetCompleteness.AssetIdentity,
+			"events_not_included", assetCompleteness.UnverifiedEvents,
+			"missing_events_mmr_indices", assetCompleteness.MissingEvents,
+			"unattributed_leaves_mmr_indices", assetCompleteness.UnattributedLeaves)
+		tracing.Exit(1)
+	}
+
+	slog.Info("complete asset history included on merkle log", logging.KeyAssetIdentity, assetCompleteness.AssetIdentity)
+}
+
+// windowDemo of the completeness of a list of datatrails events within a time window
+func windowDemo(ctx context.Context, eventsJson []byte, since string, until string) {
+
+	sinceTime, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		slog.Error("invalid start of the time window", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	untilTime, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		slog.Error("invalid end of the time window", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	windowCompleteness, err := WindowCompletenessDemo(ctx, eventsJson, sinceTime, untilTime)
+	if err != nil {
+		slog.Error("failed time window verification", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	slog.Info("leaves committed to the merkle log in the time window", "leaves", windowCompleteness.LeafCount(), "since", sinceTime, "until", untilTime)
+
+	if !windowCompleteness.Complete() {
+		slog.Error("failed time window verification",
+			"events_not_included", windowCompleteness.UnverifiedEvents,
+			"events_outside_window", windowCompleteness.OutsideEvents,
+			"omitted_leaves_mmr_indices", windowCompleteness.OmittedLeaves)
+		tracing.Exit(1)
+	}
+
+	slog.Info("complete list of events within the time window included on merkle log")
+}
+
+// policyDemo of the completeness of a selectively disclosed list of datatrails events
+func policyDemo(ctx context.Context, eventsURL string, eventsJson []byte, policyName string, identities []string) {
+
+	policy, err := NewOmissionPolicy(policyName, identities, eventsJson)
+	if err != nil {
+		slog.Error("invalid omission policy", "policy", policyName, logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	eventsAPI := events.NewEventsAPI(eventsURL, events.WithBearerToken(os.Getenv(events.BearerTokenEnv)))
+
+	policyCompleteness, err := PolicyCompletenessDemo(ctx, eventsAPI, eventsJson, policy)
+	if err != nil {
+		slog.Error("failed policy list verification", "policy", policyName, logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	if len(policyCompleteness.PermittedOmissions) > 0 {
+		slog.Info("omitted leaves permitted by the policy", "policy", policyName, logging.KeyMMRIndices, policyCompleteness.PermittedOmissions)
+	}
+
+	if !policyCompleteness.Complete() {
+		slog.Error("failed policy list verification",
+			"policy", policyName,
+			"events_not_included", policyCompleteness.UnverifiedEvents,
+			"violating_omissions_mmr_indices", policyCompleteness.ViolatingOmissions)
+		tracing.Exit(1)
+	}
+
+	slog.Info("list of events included on merkle log, omitting only leaves permitted by the policy", "policy", policyName)
+}
+
+// listDemo of the completeness of a list of datatrails events
+func listDemo(ctx context.Context, eventsJson []byte) {
+
+	entries, err := events.NewEventEntries(eventsJson)
+	if err != nil {
+		slog.Error("failed complete list verification", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		slog.Error("failed complete list verification", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		slog.Error("failed complete list verification", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	// verify each event is included on the merklelog once, the anomalies and omissions
+	//  of the list are both found from whether each event is included.
+	included, err := events.VerifyEventEntries(ctx, reader, massifHeight, entries)
+	if err != nil {
+		slog.Error("failed complete list verification", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	// First find any events in the list that are not faithful to the merklelog,
+	//  these are reported separately, by category, from any omitted events.
+	listAnomalies := FindListAnomalies(entries, included)
+
+	if listAnomalies.Found() {
+		slog.Error("failed complete list verification",
+			"duplicated_events", listAnomalies.DuplicatedEvents,
+			"reordered_events", listAnomalies.ReorderedEvents,
+			"injected_events", listAnomalies.InjectedEvents)
+		tracing.Exit(1)
+	}
+
+	// Then verify the root each event confirms, at the confirmed mmr size, matches the merklelog.
+	confirmations, err := merklelog.VerifyEventConfirmations(ctx, eventsJson)
+	if err != nil {
+		slog.Error("failed complete list verification", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	inconsistent := false
+	for _, confirmation := range confirmations {
+
+		if confirmation.Status != merklelog.ConfirmVerified {
+			slog.Error("event merklelog confirmation", logging.KeyEventIdentity, confirmation.Identity, "status", confirmation.Status)
+		}
+
+		if confirmation.SignedTreeHead != merklelog.TreeHeadAbsent {
+			slog.Info("event signed tree head", logging.KeyEventIdentity, confirmation.Identity, "status", confirmation.SignedTreeHead)
+		}
+
+		if confirmation.Unequivocal != merklelog.TreeHeadAbsent {
+			slog.Info("event unequivocal signed tree head", logging.KeyEventIdentity, confirmation.Identity, "status", confirmation.Unequivocal)
+		}
+
+		inconsistent = inconsistent || confirmation.Failed()
+	}
+
+	if inconsistent {
+		slog.Error("failed complete list verification, confirmed roots are missing, stale or inconsistent with the merkle log, or signed tree heads are invalid or inconsistent")
+		tracing.Exit(1)
+	}
+
+	omittedEvents, err := OmittedEvents(entries, included)
+	if err != nil {
+		slog.Error("failed complete list verification", logging.KeyError, err)
+		tracing.Exit(1)
 	}
 
 	// If we have any omitted events then the verification fails.
@@ -58,10 +297,86 @@ func main() {
 	//       list of events, where unrelated events are purposefully omitted.
 	//
 	if len(omittedEvents) > 0 {
-		fmt.Printf("\nFailed Complete List verification, omitted events mmrIndexs: %v\n", omittedEvents)
+		slog.Error("failed complete list verification, omitted events", logging.KeyMMRIndices, omittedEvents)
+		tracing.Exit(1)
+	}
+
+	slog.Info("complete list of events included on merkle log")
+}
+
+// Demo of the completeness of a public datatrails event
+//
+// By default the sample event list is verified, otherwise every event of the given asset,
+// or each of the given events, are fetched from the datatrails events API.
+func main() {
+
+	assetIdentity := flag.String("asset", "", "identity of the asset to verify the complete list of events of, e.g. publicassets/<uuid>")
+	eventIdentities := flag.String("events", "", "comma separated identities of the events to verify, e.g. publicassets/<uuid>/events/<uuid>")
+	eventsURL := flag.String("events-url", events.DefaultURL, "base url of the datatrails events API")
+	mode := flag.String("mode", listMode, "completeness mode, either 'list' to verify a complete list of events, 'asset' to verify the entire history of an asset, 'window' to verify a list of events covers a time window, or 'policy' to verify a list of events omits only the leaves permitted by a policy")
+	since := flag.String("since", "", "start of the time window in 'window' mode, in RFC3339 format, e.g. 2024-05-01T00:00:00Z")
+	until := flag.String("until", "", "end of the time window in 'window' mode, in RFC3339 format, e.g. 2024-05-08T00:00:00Z")
+	policy := flag.String("policy", foreignTenantPolicy, "omission policy in 'policy' mode, either 'foreign-tenant' or 'foreign-asset'")
+	policyIdentities := flag.String("policy-identities", "", "comma separated tenant, or asset, identities whose leaves may not be omitted in 'policy' mode, defaults to those of the listed events")
+	config.Flags(flag.CommandLine)
+	flag.Parse()
+
+	_, err := config.Load(flag.CommandLine, "completeness")
+	if err != nil {
+		fmt.Printf("\nFailed to load the config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !slices.Contains([]string{listMode, assetMode, windowMode, policyMode}, *mode) {
+		fmt.Printf("\n%v: %s\n\n", ErrUnknownMode, *mode)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	err = logging.Setup(config.LogLevel, config.LogFormat)
+	if err != nil {
+		fmt.Printf("\nFailed to set up logging: %v\n", err)
+		os.Exit(1)
+	}
+
+	// the massif readers log to the datatrails logger, which is set up once here, discarding its logs
+	logger.New("NOOP")
+
+	ctx, err := tracing.Setup(context.Background(), "completeness", config.TraceExporter)
+	if err != nil {
+		slog.Error("failed to set up tracing", logging.KeyError, err)
 		os.Exit(1)
 	}
+	defer tracing.Flush()
+
+	if *mode == assetMode {
+		assetDemo(ctx, *eventsURL, *assetIdentity)
+		return
+	}
+
+	eventsJson := []byte(eventList)
+	if *assetIdentity != "" || *eventIdentities != "" {
+
+		eventsAPI := events.NewEventsAPI(*eventsURL, events.WithBearerToken(os.Getenv(events.BearerTokenEnv)))
+
+		var err error
+		eventsJson, err = EventsFromAPI(ctx, eventsAPI, *assetIdentity, config.SplitList(*eventIdentities))
+		if err != nil {
+			slog.Error("failed to get the list of events", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+	}
+
+	if *mode == windowMode {
+		windowDemo(ctx, eventsJson, *since, *until)
+		return
+	}
+
+	if *mode == policyMode {
+		policyDemo(ctx, *eventsURL, eventsJson, *policy, config.SplitList(*policyIdentities))
+		return
+	}
 
-	fmt.Println("Complete List of events included on merkle log")
+	listDemo(ctx, eventsJson)
 
 }
@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
+	"os"
 	"testing"
 
+	"github.com/datatrails/go-datatrails-common/logger"
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain sets up the datatrails logger the massif readers log to, as main does
+func TestMain(m *testing.M) {
+
+	logger.New("NOOP")
+
+	os.Exit(m.Run())
+}
+
 /** TestCompletenessDemo tests the sample public events
  *   are included on the merklelog.
  *	 Also checks that the list is complete, i.e.
@@ -14,7 +25,7 @@ import (
  */
 func TestCompletenessDemo(t *testing.T) {
 
-	omittedEvents, err := CompletenessDemo([]byte(eventList))
+	omittedEvents, err := CompletenessDemo(context.Background(), []byte(eventList))
 
 	assert.Equal(t, nil, err)
 
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+)
+
+/**
+ * Policy completeness verifies a selectively disclosed list of events.
+ *
+ * In a shared merklelog, like the public tenant merklelog, a list of events will have
+ *  leaves omitted between its first and last event that belong to other tenants or assets.
+ *
+ * Each omitted leaf is attributed to the event committed at that leaf, then an omission policy
+ *  decides if that leaf was permitted to be omitted from the list.
+ */
+
+const (
+	// foreignTenantPolicy permits the omission of leaves belonging to other tenants
+	foreignTenantPolicy = "foreign-tenant"
+
+	// foreignAssetPolicy permits the omission of leaves belonging to other assets
+	foreignAssetPolicy = "foreign-asset"
+)
+
+var (
+	ErrUnknownOmissionPolicy = errors.New("unknown omission policy, expected foreign-tenant or foreign-asset")
+)
+
+// OmissionPolicy decides if the leaf at the given mmr index is permitted to be omitted from a list of events.
+//
+// leafEvent is the event committed at the leaf, or nil if the leaf could not be attributed to an event.
+type OmissionPolicy func(mmrIndex uint64, leafEvent *events.EventEntry) bool
+
+// ForeignTenantPolicy permits the omission of leaves belonging to tenants other than the given tenants.
+//
+// Leaves that can not be attributed to an event are not permitted to be omitted.
+func ForeignTenantPolicy(tenantIdentities ...string) OmissionPolicy {
+
+	return func(mmrIndex uint64, leafEvent *events.EventEntry) bool {
+
+		if leafEvent == nil {
+			return false
+		}
+
+		for _, tenantIdentity := range tenantIdentities {
+			if leafEvent.TenantIdentity == tenantIdentity {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// ForeignAssetPolicy permits the omission of leaves belonging to assets other than the given assets.
+//
+// Leaves that can not be attributed to an event are not permitted to be omitted.
+func ForeignAssetPolicy(assetIdentities ...string) OmissionPolicy {
+
+	return func(mmrIndex uint64, leafEvent *events.EventEntry) bool {
+
+		if leafEvent == nil {
+			return false
+		}
+
+		for _, assetIdentity := range assetIdentities {
+			if leafEvent.AssetIdentity == assetIdentity {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// PolicyCompleteness is the result of verifying a list of events against an omission policy.
+type PolicyCompleteness struct {
+
+	// Events are the identities of the events in the list, sorted by mmr index
+	Events []string
+
+	// UnverifiedEvents are the identities of the events in the list NOT included on the merklelog
+	UnverifiedEvents []string
+
+	// PermittedOmissions are the mmr indexes of the omitted leaves permitted by the policy
+	PermittedOmissions []uint64
+
+	// ViolatingOmissions are the mmr indexes of the omitted leaves NOT permitted by the policy
+	ViolatingOmissions []uint64
+}
+
+// Complete is true if every event in the list is included on the merklelog, and
+//
+//	every omitted leaf is permitted by the policy.
+func (pc PolicyCompleteness) Complete() bool {
+	return len(pc.UnverifiedEvents) == 0 && len(pc.ViolatingOmissions) == 0
+}
+
+// PolicyCompletenessDemo verifies the given list of events, permitting the omission of leaves
+//
+//	between the first and last event according to the given omission policy.
+func PolicyCompletenessDemo(ctx context.Context, eventsAPI *events.EventsAPI, eventsJson []byte, policy OmissionPolicy) (*PolicyCompleteness, error) {
+
+	entries, err := events.NewEventEntries(eventsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	events.SortByMMRIndex(entries)
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	policyCompleteness := &PolicyCompleteness{}
+
+	// now verify each event in the list is in the merklelog
+	public := true
+	mmrIndices := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+
+		policyCompleteness.Events = append(policyCompleteness.Events, entry.Identity)
+		mmrIndices = append(mmrIndices, entry.MMRIndex())
+		public = public && strings.HasPrefix(entry.Identity, "publicassets/")
+
+		verified, err := events.VerifyEventEntry(ctx, reader, massifHeight, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if !verified {
+			policyCompleteness.UnverifiedEvents = append(policyCompleteness.UnverifiedEvents, entry.Identity)
+		}
+	}
+
+	// now find the leaves omitted from the list, and the event committed at each
+	omittedLeaves := events.OmittedLeaves(mmrIndices)
+	if len(omittedLeaves) == 0 {
+		return policyCompleteness, nil
+	}
+
+	leafEvents, err := AttributeLeaves(ctx, eventsAPI, reader, massifHeight, public, omittedLeaves)
+	if err != nil {
+		return nil, err
+	}
+
+	policyCompleteness.PermittedOmissions, policyCompleteness.ViolatingOmissions = ApplyOmissionPolicy(policy, omittedLeaves, leafEvents)
+
+	return policyCompleteness, nil
+}
+
+// ApplyOmissionPolicy classifies each omitted leaf as permitted or violating the given policy,
+//
+//	using the event committed at each leaf, keyed by mmr index.
+func ApplyOmissionPolicy(policy OmissionPolicy, omittedLeaves []uint64, leafEvents map[uint64]events.EventEntry) (permitted []uint64, violating []uint64) {
+
+	for _, mmrIndex := range omittedLeaves {
+
+		var leafEvent *events.EventEntry
+		if entry, attributed := leafEvents[mmrIndex]; attributed {
+			leafEvent = &entry
+		}
+
+		if policy(mmrIndex, leafEvent) {
+			permitted = append(permitted, mmrIndex)
+			continue
+		}
+
+		violating = append(violating, mmrIndex)
+	}
+
+	return permitted, violating
+}
+
+// NewOmissionPolicy creates the named omission policy, permitting the omission of leaves belonging to
+//
+//	tenants, or assets, other than the given identities.
+//
+// If no identities are given, the tenants, or assets, of the events in the given list are used.
+func NewOmissionPolicy(policyName string, identities []string, eventsJson []byte) (OmissionPolicy, error) {
+
+	if len(identities) == 0 {
+
+		entries, err := events.NewEventEntries(eventsJson)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+
+			switch policyName {
+			case foreignTenantPolicy:
+				identities = append(identities, entry.TenantIdentity)
+			case foreignAssetPolicy:
+				identities = append(identities, entry.AssetIdentity)
+			}
+		}
+	}
+
+	switch policyName {
+	case foreignTenantPolicy:
+		return ForeignTenantPolicy(identities...), nil
+	case foreignAssetPolicy:
+		return ForeignAssetPolicy(identities...), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownOmissionPolicy, policyName)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyOmissionPolicy tests omitted leaves are classified as permitted
+//
+//	or violating by the foreign tenant and foreign asset policies.
+func TestApplyOmissionPolicy(t *testing.T) {
+
+	ourTenant := "tenant/f023005c-000f-4a57-b2fe-eef425f243ad"
+	ourAsset := "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6"
+
+	leafEvents := map[uint64]events.EventEntry{
+		// another tenant
+		3: {TenantIdentity: "tenant/112758ce-a8cb-4924-8df8-fcba1e31f8b0", AssetIdentity: "publicassets/fe022486-3272-4d44-aab5-765a37c17b85"},
+		// our tenant, another asset
+		7: {TenantIdentity: ourTenant, AssetIdentity: "publicassets/0e6e5e9d-6e2a-4d55-8c1f-d6b9d1c4a3b2"},
+		// our tenant, our asset
+		10: {TenantIdentity: ourTenant, AssetIdentity: ourAsset},
+	}
+
+	// the leaf at mmr index 4 is not attributed to any event
+	omittedLeaves := []uint64{3, 4, 7, 10}
+
+	permitted, violating := ApplyOmissionPolicy(ForeignTenantPolicy(ourTenant), omittedLeaves, leafEvents)
+	assert.Equal(t, []uint64{3}, permitted)
+	assert.Equal(t, []uint64{4, 7, 10}, violating)
+
+	permitted, violating = ApplyOmissionPolicy(ForeignAssetPolicy(ourAsset), omittedLeaves, leafEvents)
+	assert.Equal(t, []uint64{3, 7}, permitted)
+	assert.Equal(t, []uint64{4, 10}, violating)
+}
+
+// TestNewOmissionPolicy tests the omission policy defaults to the tenants of the listed events
+func TestNewOmissionPolicy(t *testing.T) {
+
+	policy, err := NewOmissionPolicy(foreignTenantPolicy, nil, []byte(eventList))
+	require.NoError(t, err)
+
+	assert.Equal(t, false, policy(3, &events.EventEntry{TenantIdentity: "tenant/f023005c-000f-4a57-b2fe-eef425f243ad"}))
+	assert.Equal(t, true, policy(3, &events.EventEntry{TenantIdentity: "tenant/112758ce-a8cb-4924-8df8-fcba1e31f8b0"}))
+	assert.Equal(t, false, policy(3, nil))
+
+	_, err = NewOmissionPolicy("everything", nil, []byte(eventList))
+	assert.ErrorIs(t, err, ErrUnknownOmissionPolicy)
+}
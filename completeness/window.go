@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+)
+
+/**
+ * Window completeness verifies a list of events covers exactly the leaves
+ *  committed to the merklelog within a time window.
+ *
+ * Every leaf on the merklelog has an idtimestamp, stored in the trie entry of the leaf
+ *  in its massif. The idtimestamps only ever increase along the log, so the time window
+ *  maps onto a range of leaves, found by binary search.
+ */
+
+const (
+	// trieKeyBytes is the number of bytes of the trie key at the start of a trie entry,
+	//  the idtimestamp of the leaf follows the trie key.
+	trieKeyBytes = 32
+
+	// idTimestampBytes is the number of bytes of the idtimestamp in a trie entry
+	idTimestampBytes = 8
+)
+
+// WindowCompleteness is the result of verifying a list of events covers a time window.
+type WindowCompleteness struct {
+
+	// Since is the inclusive start of the time window
+	Since time.Time
+
+	// Until is the exclusive end of the time window
+	Until time.Time
+
+	// FirstLeaf is the leaf index of the first leaf committed within the time window
+	FirstLeaf uint64
+
+	// EndLeaf is the leaf index following the last leaf committed within the time window,
+	//  the same as FirstLeaf if no leaf was committed within the time window.
+	EndLeaf uint64
+
+	// OmittedLeaves are the mmr indexes of the leaves within the time window
+	//  that are absent from the list of events.
+	OmittedLeaves []uint64
+
+	// OutsideEvents are the identities of the events in the list committed outside the time window
+	OutsideEvents []string
+
+	// UnverifiedEvents are the identities of the events in the list NOT included on the merklelog
+	UnverifiedEvents []string
+}
+
+// Complete is true if the list of events covers exactly the leaves within the time window
+func (wc WindowCompleteness) Complete() bool {
+	return len(wc.OmittedLeaves) == 0 && len(wc.OutsideEvents) == 0 && len(wc.UnverifiedEvents) == 0
+}
+
+// LeafCount is the number of leaves committed within the time window
+func (wc WindowCompleteness) LeafCount() uint64 {
+	return wc.EndLeaf - wc.FirstLeaf
+}
+
+// WindowCompletenessDemo verifies the given list of events covers exactly the leaves committed
+//
+//	to the merklelog from since, up to but not including until.
+func WindowCompletenessDemo(ctx context.Context, eventsJson []byte, since time.Time, until time.Time) (*WindowCompleteness, error) {
+
+	if !since.Before(until) {
+		return nil, fmt.Errorf("the start of the time window %v must be before the end %v", since, until)
+	}
+
+	entries, err := events.NewEventEntries(eventsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// now map the time window onto the range of leaves on the merklelog
+	leafTimes := NewLeafTimes(ctx, reader, config.TenantID, uint8(config.MassifHeight))
+
+	massifHeight, err := leafTimes.massifCache.MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	firstLeaf, endLeaf, err := leafTimes.WindowLeaves(since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	windowCompleteness := &WindowCompleteness{
+		Since:     since,
+		Until:     until,
+		FirstLeaf: firstLeaf,
+		EndLeaf:   endLeaf,
+	}
+
+	// now verify each event in the list is in the merklelog, and within the time window
+	listed := make(map[uint64]bool, len(entries))
+	for _, entry := range entries {
+
+		listed[entry.MMRIndex()] = true
+
+		verified, err := events.VerifyEventEntry(ctx, reader, massifHeight, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if !verified {
+			windowCompleteness.UnverifiedEvents = append(windowCompleteness.UnverifiedEvents, entry.Identity)
+		}
+
+		if entry.MMRIndex() < mmr.TreeIndex(firstLeaf) || entry.MMRIndex() >= mmr.TreeIndex(endLeaf) {
+			windowCompleteness.OutsideEvents = append(windowCompleteness.OutsideEvents, entry.Identity)
+		}
+	}
+
+	// finally find the leaves within the time window absent from the list
+	for leafIndex := firstLeaf; leafIndex < endLeaf; leafIndex++ {
+
+		mmrIndex := mmr.TreeIndex(leafIndex)
+		if !listed[mmrIndex] {
+			windowCompleteness.OmittedLeaves = append(windowCompleteness.OmittedLeaves, mmrIndex)
+		}
+	}
+
+	return windowCompleteness, nil
+}
+
+// LeafTimes reads the time each leaf was committed to the merklelog of a tenant,
+//
+//	from the idtimestamps stored in the massifs.
+type LeafTimes struct {
+	massifCache *merklelog.MassifCache
+}
+
+// NewLeafTimes creates a LeafTimes for the merklelog of the given tenant, of the given massif height,
+//
+//	or the massif height discovered from the first massif if the given massif height is 0.
+func NewLeafTimes(ctx context.Context, reader azblob.Reader, tenantID string, massifHeight uint8) *LeafTimes {
+	return &LeafTimes{
+		massifCache: merklelog.NewMassifCache(ctx, reader, tenantID, massifHeight),
+	}
+}
+
+// WindowLeaves returns the range of the leaves committed to the merklelog from since,
+//
+//	up to but not including until, as the leaf index of the first leaf, and the leaf
+//	index following the last leaf.
+func (lt *LeafTimes) WindowLeaves(since time.Time, until time.Time) (first uint64, end uint64, err error) {
+
+	headMassif, err := lt.massifCache.HeadMassif()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	leafCount := mmr.LeafCount(headMassif.RangeCount())
+
+	first, err = lt.searchLeaves(leafCount, since)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err = lt.searchLeaves(leafCount, until)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return first, end, nil
+}
+
+// LeafTime returns the time the leaf at the given mmr index was committed to the merklelog
+func (lt *LeafTimes) LeafTime(mmrIndex uint64) (time.Time, error) {
+
+	massifContext, err := lt.massifCache.Massif(mmrIndex)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	trieEntry, err := massifContext.GetTrieEntry(mmrIndex)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	idTimestamp := binary.BigEndian.Uint64(trieEntry[trieKeyBytes : trieKeyBytes+idTimestampBytes])
+
+	return time.UnixMilli(IDTimestampUnixMilli(idTimestamp, uint8(massifContext.Start.CommitmentEpoch))), nil
+}
+
+// searchLeaves returns the leaf index of the first leaf committed at or after the given time,
+//
+//	or the leaf count if every leaf was committed before the given time.
+func (lt *LeafTimes) searchLeaves(leafCount uint64, t time.Time) (uint64, error) {
+
+	var searchErr error
+
+	leafIndex := sort.Search(int(leafCount), func(i int) bool {
+
+		if searchErr != nil {
+			return true
+		}
+
+		leafTime, err := lt.LeafTime(mmr.TreeIndex(uint64(i)))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+
+		return !leafTime.Before(t)
+	})
+
+	if searchErr != nil {
+		return 0, searchErr
+	}
+
+	return uint64(leafIndex), nil
+}
+
+// IDTimestampUnixMilli converts an idtimestamp, committed in the given epoch, to unix milliseconds.
+//
+// The top 40 bits of an idtimestamp are the milliseconds since the start of the epoch,
+// where each epoch is 2^40 milliseconds long.
+func IDTimestampUnixMilli(idTimestamp uint64, epoch uint8) int64 {
+	return int64(epoch)<<40 | int64(idTimestamp>>24)
+}
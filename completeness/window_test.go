@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIDTimestampUnixMilli tests the idtimestamp of the first sample event
+//
+//	converts to the time the event was committed.
+func TestIDTimestampUnixMilli(t *testing.T) {
+
+	// the idtimestamp 018f54c34a730ce300 is the epoch 01 followed by the idtimestamp 8f54c34a730ce300
+	unixMilli := IDTimestampUnixMilli(0x8f54c34a730ce300, 1)
+
+	assert.Equal(t, int64(1715114035827), unixMilli)
+	assert.Equal(t, "2024-05-07T20:33:55.827Z", time.UnixMilli(unixMilli).UTC().Format(time.RFC3339Nano))
+}
+
+// TestWindowCompletenessDemo tests the sample events cover exactly the leaves
+//
+//	committed between the first and the last sample event.
+func TestWindowCompletenessDemo(t *testing.T) {
+
+	// the idtimestamps of the first (mmrIndex 483) and last (mmrIndex 511) sample event
+	since := time.UnixMilli(IDTimestampUnixMilli(0x8f54bee2390ce300, 1))
+	until := time.UnixMilli(IDTimestampUnixMilli(0x8f54c34a730ce300, 1) + 1)
+
+	windowCompleteness, err := WindowCompletenessDemo(context.Background(), []byte(eventList), since, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(483), mmr.TreeIndex(windowCompleteness.FirstLeaf))
+	assert.Equal(t, uint64(13), windowCompleteness.LeafCount())
+	assert.Equal(t, true, windowCompleteness.Complete())
+
+	// a window ending before the last sample event omits nothing, but the last event is outside it
+	until = time.UnixMilli(IDTimestampUnixMilli(0x8f54c34a730ce300, 1))
+
+	windowCompleteness, err = WindowCompletenessDemo(context.Background(), []byte(eventList), since, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(12), windowCompleteness.LeafCount())
+	assert.Equal(t, 0, len(windowCompleteness.OmittedLeaves))
+	assert.Equal(t, []string{"publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/9a192afe-9253-44d7-8585-c48f237f2134"}, windowCompleteness.OutsideEvents)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+)
+
+/**
+ * Consistency proof exports the consistency proof between two signed log states as a portable file,
+ *  so the consistency of the log can be published as evidence, and re-verified by third party witnesses.
+ *
+ * The consistency proof file holds:
+ *  1. the peaks of the older log state, which bag to its root.
+ *  2. the peaks of the newer log state, which bag to its root.
+ *  3. the proof path, from the peaks of the older log state to the root of the newer log state.
+ *
+ * The consistency proof file is re-verified offline with just the two signed log states, no access
+ *  to the merklelog is needed.
+ */
+
+const (
+	// proofFilePerm is the file permission of exported consistency proofs and signed log states
+	proofFilePerm = 0644
+)
+
+var (
+	ErrProofStateMismatch = errors.New("the consistency proof is not between the mmr sizes of the signed log states")
+)
+
+// ConsistencyProofFile is the portable consistency proof between two log states of a tenant's merklelog
+type ConsistencyProofFile struct {
+	TenantID string `json:"tenant_id"`
+
+	// MMRSizeA is the mmr size of the older log state
+	MMRSizeA uint64 `json:"mmr_size_a"`
+
+	// MMRSizeB is the mmr size of the newer log state
+	MMRSizeB uint64 `json:"mmr_size_b"`
+
+	// PeaksA are the peaks of the older log state
+	PeaksA [][]byte `json:"peaks_a"`
+
+	// PeaksB are the peaks of the newer log state
+	PeaksB [][]byte `json:"peaks_b"`
+
+	// Path is the proof path from the peaks of the older log state to the root of the newer log state
+	Path [][]byte `json:"path"`
+}
+
+// ConsistencyEvidence is the consistency proof between two log states, with the signed log state of each
+type ConsistencyEvidence struct {
+	Proof ConsistencyProofFile
+
+	// SignedStateA is the COSE Sign1 signed log state of the older log state
+	SignedStateA []byte
+
+	// SignedStateB is the COSE Sign1 signed log state of the newer log state
+	SignedStateB []byte
+}
+
+// ConsistencyEvidenceDemo creates the consistency proof between the existing signed log state,
+//
+//	and the newer signed log state selected by the given new state.
+func ConsistencyEvidenceDemo(ctx context.Context, newState merklelog.NewStateSelector) (*ConsistencyEvidence, error) {
+
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := massifs.NewRootSignerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	massifCache := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight))
+
+	signedState, err := merklelog.NewSignedState(ctx, reader, codec, massifCache, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	signedStateB, err := signedState.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+
+	logStateA, err := merklelog.ExistingSignedState(ctx, trustedSealFile, config.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logStateB, err := verifiedLogState(ctx, signedStateB)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := NewConsistencyProofFile(massifCache, config.TenantID, logStateA, logStateB)
+	if err != nil {
+		return nil, err
+	}
+
+	signedStateA, err := merklelog.TrustedSeal(trustedSealFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsistencyEvidence{
+		Proof:        *proof,
+		SignedStateA: signedStateA,
+		SignedStateB: signedStateB,
+	}, nil
+}
+
+// NewConsistencyProofFile creates the consistency proof between the given older and newer log states,
+//
+//	from the massif data of the tenant's merklelog, across massif boundaries.
+func NewConsistencyProofFile(massifCache *merklelog.MassifCache, tenantID string, logStateA *massifs.MMRState, logStateB *massifs.MMRState) (*ConsistencyProofFile, error) {
+
+	if logStateA.MMRSize == 0 || logStateA.MMRSize > logStateB.MMRSize {
+		return nil, fmt.Errorf("%w: %d, %d", merklelog.ErrLogStatesOutOfOrder, logStateA.MMRSize, logStateB.MMRSize)
+	}
+
+	// the log states may be in different massifs, so read each node from the massif that holds it
+	store := merklelog.NewMassifStore(massifCache)
+
+	peaksA, err := mmr.PeakBagRHS(store, nil, 0, mmr.Peaks(logStateA.MMRSize))
+	if err != nil {
+		return nil, err
+	}
+
+	peaksB, err := mmr.PeakBagRHS(store, nil, 0, mmr.Peaks(logStateB.MMRSize))
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := mmr.IndexConsistencyProof(logStateA.MMRSize, logStateB.MMRSize, store, sha256.New())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsistencyProofFile{
+		TenantID: tenantID,
+		MMRSizeA: proof.MMRSizeA,
+		MMRSizeB: proof.MMRSizeB,
+		PeaksA:   peaksA,
+		PeaksB:   peaksB,
+		Path:     proof.Path,
+	}, nil
+}
+
+// VerifyConsistencyProofFile verifies the given consistency proof file offline, with just the two signed log states.
+//
+// The signed log states are verified with the datatrails seal verification key, then the peaks of each log state
+// are verified to bag to its root, and the proof path to lead from the peaks of the older log state to the root
+// of the newer log state.
+func VerifyConsistencyProofFile(ctx context.Context, proofJson []byte, signedStateA []byte, signedStateB []byte) (bool, error) {
+
+	proof := ConsistencyProofFile{}
+	err := json.Unmarshal(proofJson, &proof)
+	if err != nil {
+		return false, err
+	}
+
+	logStateA, err := verifiedLogState(ctx, signedStateA)
+	if err != nil {
+		return false, err
+	}
+
+	logStateB, err := verifiedLogState(ctx, signedStateB)
+	if err != nil {
+		return false, err
+	}
+
+	if proof.MMRSizeA != logStateA.MMRSize || proof.MMRSizeB != logStateB.MMRSize {
+		return false, fmt.Errorf("%w: proof %d, %d, signed log states %d, %d",
+			ErrProofStateMismatch, proof.MMRSizeA, proof.MMRSizeB, logStateA.MMRSize, logStateB.MMRSize)
+	}
+
+	// the peaks of the newer log state are published with the proof, so they must be those of its root too
+	if !bytes.Equal(mmr.HashPeaksRHS(sha256.New(), proof.PeaksB), logStateB.Root) {
+		return false, nil
+	}
+
+	consistencyProof := mmr.ConsistencyProof{
+		MMRSizeA: proof.MMRSizeA,
+		MMRSizeB: proof.MMRSizeB,
+		Path:     proof.Path,
+	}
+
+	return mmr.VerifyConsistency(sha256.New(), proof.PeaksA, consistencyProof, logStateA.Root, logStateB.Root), nil
+}
+
+// WriteConsistencyEvidence writes the consistency proof file, and, if their paths are given, the signed log states
+func WriteConsistencyEvidence(evidence *ConsistencyEvidence, proofPath string, signedStateAPath string, signedStateBPath string) error {
+
+	proofJson, err := json.MarshalIndent(evidence.Proof, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(proofPath, proofJson, proofFilePerm)
+	if err != nil {
+		return err
+	}
+
+	if signedStateAPath != "" {
+		err = os.WriteFile(signedStateAPath, evidence.SignedStateA, proofFilePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	if signedStateBPath != "" {
+		err = os.WriteFile(signedStateBPath, evidence.SignedStateB, proofFilePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifiedLogState verifies the given signed log state with the datatrails seal verification key,
+//
+//	and unmarshals it into a golang data structure.
+func verifiedLogState(ctx context.Context, signedStateCbor []byte) (*massifs.MMRState, error) {
+
+	verificationKey, err := merklelog.VerificationKeyFromFile(config.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return merklelog.VerifiedLogState(ctx, signedStateCbor, verificationKey)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConsistencyEvidenceDemo tests the exported consistency proof re-verifies offline
+//
+//	with just the two signed log states.
+func TestConsistencyEvidenceDemo(t *testing.T) {
+
+	evidence, err := ConsistencyEvidenceDemo(context.Background(), merklelog.NewStateSelector{})
+	require.NoError(t, err)
+
+	assert.Equal(t, config.TenantID, evidence.Proof.TenantID)
+	assert.Less(t, evidence.Proof.MMRSizeA, evidence.Proof.MMRSizeB)
+	assert.NotEmpty(t, evidence.Proof.PeaksA)
+	assert.NotEmpty(t, evidence.Proof.PeaksB)
+
+	// export the evidence, then re-verify it from the files
+	dir := t.TempDir()
+	proofPath := filepath.Join(dir, "proof.json")
+	sealAPath := filepath.Join(dir, "seal-a.cbor")
+	sealBPath := filepath.Join(dir, "seal-b.cbor")
+
+	err = WriteConsistencyEvidence(evidence, proofPath, sealAPath, sealBPath)
+	require.NoError(t, err)
+
+	verified, err := verifyConsistencyProofFiles(context.Background(), proofPath, sealAPath, sealBPath)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, verified)
+
+	// a tampered peak of the older log state no longer bags to its root
+	proofJson, err := os.ReadFile(proofPath)
+	require.NoError(t, err)
+
+	tampered := ConsistencyProofFile{}
+	require.NoError(t, json.Unmarshal(proofJson, &tampered))
+	tampered.PeaksA[0][0] ^= 0xff
+
+	tamperedJson, err := json.Marshal(tampered)
+	require.NoError(t, err)
+
+	verified, err = VerifyConsistencyProofFile(context.Background(), tamperedJson, evidence.SignedStateA, evidence.SignedStateB)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, verified)
+
+	// a tampered peak of the newer log state no longer bags to its root either
+	tampered = ConsistencyProofFile{}
+	require.NoError(t, json.Unmarshal(proofJson, &tampered))
+	tampered.PeaksB[len(tampered.PeaksB)-1][0] ^= 0xff
+
+	tamperedJson, err = json.Marshal(tampered)
+	require.NoError(t, err)
+
+	verified, err = VerifyConsistencyProofFile(context.Background(), tamperedJson, evidence.SignedStateA, evidence.SignedStateB)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, verified)
+
+	// the signed log states must be those the proof is between
+	_, err = VerifyConsistencyProofFile(context.Background(), proofJson, evidence.SignedStateB, evidence.SignedStateA)
+	assert.ErrorIs(t, err, ErrProofStateMismatch)
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+)
+
+/**
+ * Evidence captures cryptographic proof of misbehaviour when a newer log state is NOT consistent
+ *  with an older log state, so the failure can be escalated to datatrails, or a regulator.
+ *
+ * The evidence package is a directory holding:
+ *  1. seal-a.cbor and seal-b.cbor, the two signed log states.
+ *  2. massifs/<massif index>.log, the bytes of every massif read while verifying the consistency.
+ *  3. evidence.json, the roots, the peaks computed from the log, and the peaks that diverged.
+ *  4. explanation.txt, a human readable explanation of the inconsistency.
+ *
+ * Peaks are only known to have diverged if the peaks of the older log state are known from a trusted
+ *  source, e.g. a consistency proof file exported when the older log state was verified.
+ */
+
+const (
+	// evidenceDirPerm is the file permission of the directories of an evidence package
+	evidenceDirPerm = 0755
+)
+
+var (
+	ErrTrustedPeaksMismatch = errors.New("the trusted peaks are not the peaks of the older log state")
+)
+
+// EvidenceOptions configure the evidence package written when the log states are inconsistent
+type EvidenceOptions struct {
+
+	// Dir to write the evidence package to, no evidence package is written if empty
+	Dir string
+
+	// TrustedPeaksA are the peaks of the older log state from a trusted source, if known
+	TrustedPeaksA [][]byte
+}
+
+// DivergedPeak is a peak of the older log state whose node on the log differs from the trusted peak
+type DivergedPeak struct {
+
+	// Peak is the position of the peak in the peaks of the older log state, highest peak first
+	Peak int `json:"peak"`
+
+	// MMRIndex of the peak
+	MMRIndex uint64 `json:"mmr_index"`
+
+	// Trusted is the trusted peak hash
+	Trusted []byte `json:"trusted"`
+
+	// Log is the peak hash on the log, empty if it could not be read
+	Log []byte `json:"log"`
+}
+
+// InconsistencyEvidence is the evidence that a newer log state is not consistent with an older log state
+type InconsistencyEvidence struct {
+	TenantID string `json:"tenant_id"`
+
+	// MMRSizeA and RootA are the mmr size and root of the older signed log state
+	MMRSizeA uint64 `json:"mmr_size_a"`
+	RootA    []byte `json:"root_a"`
+
+	// MMRSizeB and RootB are the mmr size and root of the newer signed log state
+	MMRSizeB uint64 `json:"mmr_size_b"`
+	RootB    []byte `json:"root_b"`
+
+	// LogRootA and LogRootB are the roots recomputed from the log at each mmr size, empty if they could not be recomputed
+	LogRootA []byte `json:"log_root_a"`
+	LogRootB []byte `json:"log_root_b"`
+
+	// PeaksA are the peaks of the older log state read from the log, highest peak first
+	PeaksA [][]byte `json:"peaks_a"`
+
+	// TrustedPeaksA are the peaks of the older log state from a trusted source
+	TrustedPeaksA [][]byte `json:"trusted_peaks_a,omitempty"`
+
+	// DivergedPeaks are the peaks of the older log state that differ from the trusted peaks
+	DivergedPeaks []DivergedPeak `json:"diverged_peaks"`
+
+	// Explanation is the human readable explanation of the inconsistency
+	Explanation []string `json:"explanation"`
+}
+
+// NewInconsistencyEvidence explains why the newer log state B is not consistent with the older log state A,
+//
+//	by recomputing the roots and peaks of both log states from the log.
+//
+// Nodes that can not be read from the log are explained, rather than failing the evidence capture.
+func NewInconsistencyEvidence(store merklelog.NodeStore, tenantID string, logStateA *massifs.MMRState, logStateB *massifs.MMRState, trustedPeaksA [][]byte) *InconsistencyEvidence {
+
+	evidence := &InconsistencyEvidence{
+		TenantID:      tenantID,
+		MMRSizeA:      logStateA.MMRSize,
+		RootA:         logStateA.Root,
+		MMRSizeB:      logStateB.MMRSize,
+		RootB:         logStateB.Root,
+		TrustedPeaksA: trustedPeaksA,
+		DivergedPeaks: []DivergedPeak{},
+	}
+
+	explain := func(format string, args ...any) {
+		evidence.Explanation = append(evidence.Explanation, fmt.Sprintf(format, args...))
+	}
+
+	// the peaks are one based positions, the mmr indices of the peaks are one less
+	peakIndices := []uint64{}
+	for _, peak := range mmr.Peaks(logStateA.MMRSize) {
+		peakIndices = append(peakIndices, peak-1)
+	}
+
+	// the peaks of the older log state, as the log has them now
+	for _, peakIndex := range peakIndices {
+
+		peak, err := store.Get(peakIndex)
+		if err != nil {
+			explain("the peak at mmr index %d of the older log state can not be read from the log: %v", peakIndex, err)
+		}
+
+		evidence.PeaksA = append(evidence.PeaksA, peak)
+	}
+
+	if len(trustedPeaksA) > 0 && len(trustedPeaksA) != len(peakIndices) {
+		explain("the %d trusted peaks are not the %d peaks of the older log state at mmr size %d",
+			len(trustedPeaksA), len(peakIndices), logStateA.MMRSize)
+	}
+
+	if len(trustedPeaksA) == len(peakIndices) {
+		for peak, peakIndex := range peakIndices {
+
+			if bytes.Equal(trustedPeaksA[peak], evidence.PeaksA[peak]) {
+				continue
+			}
+
+			evidence.DivergedPeaks = append(evidence.DivergedPeaks, DivergedPeak{
+				Peak:     peak,
+				MMRIndex: peakIndex,
+				Trusted:  trustedPeaksA[peak],
+				Log:      evidence.PeaksA[peak],
+			})
+
+			explain("peak %d of the older log state, at mmr index %d, has diverged from the trusted peak: trusted %x, log %x",
+				peak, peakIndex, trustedPeaksA[peak], evidence.PeaksA[peak])
+		}
+	}
+
+	// now recompute both roots from the log, and compare them to the signed roots
+	var err error
+	evidence.LogRootA, err = mmr.GetRoot(logStateA.MMRSize, store, sha256.New())
+	switch {
+	case err != nil:
+		explain("the root of the older log state at mmr size %d can not be recomputed from the log: %v", logStateA.MMRSize, err)
+	case !bytes.Equal(evidence.LogRootA, logStateA.Root):
+		explain("the log no longer reproduces the signed root of the older log state at mmr size %d, the log before the older log state has been rewritten", logStateA.MMRSize)
+	}
+
+	evidence.LogRootB, err = mmr.GetRoot(logStateB.MMRSize, store, sha256.New())
+	switch {
+	case err != nil:
+		explain("the root of the newer log state at mmr size %d can not be recomputed from the log: %v", logStateB.MMRSize, err)
+	case !bytes.Equal(evidence.LogRootB, logStateB.Root):
+		explain("the log does not reproduce the signed root of the newer log state at mmr size %d, the newer log state signs a different log than the one published", logStateB.MMRSize)
+	}
+
+	if len(evidence.Explanation) == 0 {
+		explain("the log reproduces both signed roots, but the peaks of the older log state are not included in the newer log state")
+	}
+
+	return evidence
+}
+
+// TrustedPeaksFromProofFile gets the peaks of the older log state, of the given mmr size,
+//
+//	from a consistency proof file exported when the older log state was verified.
+func TrustedPeaksFromProofFile(proofJson []byte, mmrSizeA uint64) ([][]byte, error) {
+
+	proof := ConsistencyProofFile{}
+
+	err := json.Unmarshal(proofJson, &proof)
+	if err != nil {
+		return nil, err
+	}
+
+	if proof.MMRSizeA != mmrSizeA {
+		return nil, fmt.Errorf("%w: the consistency proof file has mmr size %d, the older log state has mmr size %d",
+			ErrTrustedPeaksMismatch, proof.MMRSizeA, mmrSizeA)
+	}
+
+	return proof.PeaksA, nil
+}
+
+// WriteEvidencePackage writes the evidence package of an inconsistency to the given directory
+func WriteEvidencePackage(dir string, evidence *InconsistencyEvidence, signedStateA []byte, signedStateB []byte, massifsRead []*massifs.MassifContext) error {
+
+	massifDir := filepath.Join(dir, "massifs")
+
+	err := os.MkdirAll(massifDir, evidenceDirPerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "seal-a.cbor"), signedStateA, proofFilePerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "seal-b.cbor"), signedStateB, proofFilePerm)
+	if err != nil {
+		return err
+	}
+
+	for _, massifContext := range massifsRead {
+
+		massifFile := filepath.Join(massifDir, fmt.Sprintf("%016d.log", massifContext.Start.MassifIndex))
+
+		err = os.WriteFile(massifFile, massifContext.Data, proofFilePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	evidenceJson, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "evidence.json"), evidenceJson, proofFilePerm)
+	if err != nil {
+		return err
+	}
+
+	explanation := strings.Join(evidence.Explanation, "\n") + "\n"
+
+	return os.WriteFile(filepath.Join(dir, "explanation.txt"), []byte(explanation), proofFilePerm)
+}
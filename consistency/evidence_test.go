@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRewrittenLog creates a synthetic log with the given seed, where the leaf at the given leaf index is rewritten
+func newRewrittenLog(t *testing.T, seed string, rewrittenLeafIndex int) *syntheticLog {
+
+	log := &syntheticLog{}
+
+	for leafIndex := 0; leafIndex < syntheticLeafCount; leafIndex++ {
+
+		leaf := sha256.Sum256([]byte(fmt.Sprintf("%s leaf %d", seed, leafIndex)))
+		if leafIndex == rewrittenLeafIndex {
+			leaf = sha256.Sum256([]byte(fmt.Sprintf("%s rewritten leaf %d", seed, leafIndex)))
+		}
+
+		_, err := mmr.AddHashedLeaf(log, sha256.New(), leaf[:])
+		require.NoError(t, err)
+	}
+
+	return log
+}
+
+// TestNewInconsistencyEvidence_DivergedPeak tests the evidence of a log rewritten after the older log state
+//
+//	identifies the peak of the older log state that diverged.
+func TestNewInconsistencyEvidence_DivergedPeak(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+
+	// 5 leaves has 2 peaks, the first 4 leaves, then leaf 4 on its own
+	logStateA := log.logState(t, 5)
+	trustedPeaksA, err := mmr.PeakBagRHS(log, nil, 0, mmr.Peaks(logStateA.MMRSize))
+	require.NoError(t, err)
+
+	// the log is rewritten at leaf 4, and the newer log state signs the rewritten log
+	rewrittenLog := newRewrittenLog(t, "synthetic", 4)
+	logStateB := rewrittenLog.logState(t, syntheticLeafCount)
+
+	verified, err := merklelog.VerifyLogConsistency(rewrittenLog, logStateA, logStateB)
+	require.NoError(t, err)
+	require.Equal(t, false, verified)
+
+	evidence := NewInconsistencyEvidence(rewrittenLog, config.TenantID, logStateA, logStateB, trustedPeaksA)
+
+	assert.Equal(t, 2, len(evidence.PeaksA))
+	assert.Equal(t, trustedPeaksA[0], evidence.PeaksA[0])
+
+	require.Equal(t, 1, len(evidence.DivergedPeaks))
+	assert.Equal(t, 1, evidence.DivergedPeaks[0].Peak)
+	assert.Equal(t, uint64(7), evidence.DivergedPeaks[0].MMRIndex)
+	assert.Equal(t, trustedPeaksA[1], evidence.DivergedPeaks[0].Trusted)
+	assert.NotEqual(t, trustedPeaksA[1], evidence.DivergedPeaks[0].Log)
+
+	// the rewritten log no longer reproduces the older root, but does reproduce the newer root
+	assert.NotEqual(t, logStateA.Root, evidence.LogRootA)
+	assert.Equal(t, logStateB.Root, evidence.LogRootB)
+
+	assert.Equal(t, 2, len(evidence.Explanation))
+	assert.Contains(t, evidence.Explanation[0], "peak 1 of the older log state, at mmr index 7, has diverged")
+	assert.Contains(t, evidence.Explanation[1], "has been rewritten")
+}
+
+// TestNewInconsistencyEvidence_Equivocation tests the evidence of a newer log state that signs a different log than the one published
+func TestNewInconsistencyEvidence_Equivocation(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	forkedLog := newSyntheticLog(t, "forked")
+
+	logStateA := log.logState(t, 2)
+	logStateB := forkedLog.logState(t, syntheticLeafCount)
+
+	evidence := NewInconsistencyEvidence(log, config.TenantID, logStateA, logStateB, nil)
+
+	// without trusted peaks no peak is known to have diverged
+	assert.Equal(t, 0, len(evidence.DivergedPeaks))
+	assert.Equal(t, logStateA.Root, evidence.LogRootA)
+
+	require.Equal(t, 1, len(evidence.Explanation))
+	assert.Contains(t, evidence.Explanation[0], "signs a different log than the one published")
+}
+
+// TestNewInconsistencyEvidence_BeyondLog tests the evidence of a newer log state beyond the end of the log is explained
+func TestNewInconsistencyEvidence_BeyondLog(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+
+	logStateA := log.logState(t, 2)
+	logStateB := &massifs.MMRState{MMRSize: mmr.TreeIndex(syntheticLeafCount * 2), Root: []byte("root")}
+
+	evidence := NewInconsistencyEvidence(log, config.TenantID, logStateA, logStateB, nil)
+
+	require.Equal(t, 1, len(evidence.Explanation))
+	assert.Contains(t, evidence.Explanation[0], "can not be recomputed from the log")
+}
+
+// TestTrustedPeaksFromProofFile tests the trusted peaks are only taken from a proof file of the older log state
+func TestTrustedPeaksFromProofFile(t *testing.T) {
+
+	proofJson, err := json.Marshal(ConsistencyProofFile{MMRSizeA: 8, PeaksA: [][]byte{{1}, {2}}})
+	require.NoError(t, err)
+
+	peaks, err := TrustedPeaksFromProofFile(proofJson, 8)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, [][]byte{{1}, {2}}, peaks)
+
+	_, err = TrustedPeaksFromProofFile(proofJson, 11)
+
+	assert.ErrorIs(t, err, ErrTrustedPeaksMismatch)
+}
+
+// TestWriteEvidencePackage tests the evidence package holds the seals, massifs, evidence and explanation
+func TestWriteEvidencePackage(t *testing.T) {
+
+	evidenceDir := filepath.Join(t.TempDir(), "evidence")
+
+	evidence := &InconsistencyEvidence{
+		TenantID:    config.TenantID,
+		MMRSizeA:    8,
+		Explanation: []string{"first reason", "second reason"},
+	}
+
+	massifsRead := []*massifs.MassifContext{
+		{Start: massifs.MassifStart{MassifIndex: 0}, Data: []byte("massif 0")},
+		{Start: massifs.MassifStart{MassifIndex: 3}, Data: []byte("massif 3")},
+	}
+
+	err := WriteEvidencePackage(evidenceDir, evidence, []byte("seal a"), []byte("seal b"), massifsRead)
+	require.NoError(t, err)
+
+	readFile := func(name ...string) string {
+		content, err := os.ReadFile(filepath.Join(append([]string{evidenceDir}, name...)...))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	assert.Equal(t, "seal a", readFile("seal-a.cbor"))
+	assert.Equal(t, "seal b", readFile("seal-b.cbor"))
+	assert.Equal(t, "massif 0", readFile("massifs", "0000000000000000.log"))
+	assert.Equal(t, "massif 3", readFile("massifs", "0000000000000003.log"))
+	assert.Equal(t, "first reason\nsecond reason\n", readFile("explanation.txt"))
+
+	written := InconsistencyEvidence{}
+	require.NoError(t, json.Unmarshal([]byte(readFile("evidence.json")), &written))
+	assert.Equal(t, *evidence, written)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * Gossip exchanges the latest trusted seals of verifiers, e.g. in different teams, to detect split view attacks,
+ *  where the log shows different verifiers different, inconsistent, views of itself.
+ *
+ * Each verifier serves its latest trusted seal over http, and fetches the latest trusted seal of each of its peers.
+ *  A peer's seal must be signed by datatrails, and consistent with ours, whichever of the two is newer.
+ */
+
+const (
+	// GossipSealPath is the http path the latest trusted seal is served on
+	GossipSealPath = "/gossip/seal"
+
+	// gossipContentType is the content type of the served seal, a COSE Sign1 message
+	gossipContentType = "application/cose"
+
+	// maxGossipSealBytes is the most bytes of a peer's seal read, a seal is only a few hundred bytes
+	maxGossipSealBytes = 16 * 1024
+
+	// gossipTimeout is how long fetching the seal of a peer may take, so an unresponsive peer can't stall the monitor
+	gossipTimeout = 30 * time.Second
+
+	// gossipReadHeaderTimeout is how long a peer may take to send the headers of its request for our seal
+	gossipReadHeaderTimeout = 5 * time.Second
+)
+
+var (
+	ErrPeerSealUnavailable = errors.New("the peer did not serve its latest trusted seal")
+	ErrPeerSealTooLarge    = errors.New("the peer served a seal too large to be a seal")
+)
+
+// TrustedSealSource is the source of the latest trusted seal served to peers
+type TrustedSealSource interface {
+	LatestTrustedSeal() ([]byte, *massifs.MMRState)
+}
+
+// NewGossipHandler creates the http handler serving the latest trusted seal of the given source
+func NewGossipHandler(source TrustedSealSource) http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(GossipSealPath, func(w http.ResponseWriter, r *http.Request) {
+
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		seal, _ := source.LatestTrustedSeal()
+		if len(seal) == 0 {
+			http.Error(w, "no trusted seal yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", gossipContentType)
+		w.Write(seal)
+	})
+
+	return mux
+}
+
+// PeerView is a peer's view of the log, its latest trusted seal, compared with ours
+type PeerView struct {
+
+	// Peer is the base url of the peer
+	Peer string
+
+	// LogState is the log state of the peer's latest trusted seal
+	LogState *massifs.MMRState
+
+	// Reason the peer's view is inconsistent with ours, empty if consistent
+	Reason string
+
+	// Err is the reason the peer's view could not be compared with ours, e.g. the peer is unreachable
+	Err error
+}
+
+// Consistent is true if the peer's view was compared, and is consistent with ours
+func (pv PeerView) Consistent() bool {
+	return pv.Err == nil && pv.Reason == ""
+}
+
+// GossipClient fetches the latest trusted seals of peers
+type GossipClient struct {
+	peers      []string
+	httpClient *http.Client
+}
+
+// NewGossipClient creates a gossip client for the peers with the given base urls
+func NewGossipClient(peers []string, httpClient *http.Client) *GossipClient {
+	return &GossipClient{
+		peers:      peers,
+		httpClient: httpClient,
+	}
+}
+
+// FetchSeal fetches the latest trusted seal of the peer with the given base url
+func (gc *GossipClient) FetchSeal(ctx context.Context, peer string) ([]byte, error) {
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(peer, "/")+GossipSealPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := gc.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s responded %s", ErrPeerSealUnavailable, peer, response.Status)
+	}
+
+	seal, err := io.ReadAll(io.LimitReader(response.Body, maxGossipSealBytes+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seal) > maxGossipSealBytes {
+		return nil, fmt.Errorf("%w: %s served more than %d bytes", ErrPeerSealTooLarge, peer, maxGossipSealBytes)
+	}
+
+	return seal, nil
+}
+
+// CompareViews fetches the latest trusted seal of every peer, verifying each is signed by datatrails,
+//
+//	and consistent with our latest trusted log state.
+func (gc *GossipClient) CompareViews(ctx context.Context, ourState *massifs.MMRState, verifier merklelog.ConsistencyVerifier) []PeerView {
+
+	peerViews := make([]PeerView, 0, len(gc.peers))
+	for _, peer := range gc.peers {
+
+		peerView := PeerView{Peer: peer}
+
+		peerSeal, err := gc.FetchSeal(ctx, peer)
+		if err == nil {
+			peerView.LogState, err = verifiedLogState(ctx, peerSeal)
+		}
+
+		if err == nil {
+			peerView.Reason, err = CompareLogStates(ourState, peerView.LogState, verifier)
+		}
+
+		peerView.Err = err
+		peerViews = append(peerViews, peerView)
+	}
+
+	return peerViews
+}
+
+// CompareLogStates verifies the two log states are consistent, whichever of the two is newer,
+//
+//	returning the reason they are inconsistent, or empty if consistent.
+func CompareLogStates(logState *massifs.MMRState, otherLogState *massifs.MMRState, verifier merklelog.ConsistencyVerifier) (string, error) {
+
+	if otherLogState.MMRSize < logState.MMRSize {
+		return sealChainLink(otherLogState, logState, verifier)
+	}
+
+	return sealChainLink(logState, otherLogState, verifier)
+}
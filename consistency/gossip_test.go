@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSealSource is a trusted seal source serving a fixed seal
+type testSealSource struct {
+	seal []byte
+}
+
+// LatestTrustedSeal gets the fixed seal
+func (tss *testSealSource) LatestTrustedSeal() ([]byte, *massifs.MMRState) {
+	return tss.seal, nil
+}
+
+// TestGossipHandler tests the latest trusted seal is served to peers
+func TestGossipHandler(t *testing.T) {
+
+	source := &testSealSource{}
+
+	server := httptest.NewServer(NewGossipHandler(source))
+	defer server.Close()
+
+	gossipClient := NewGossipClient([]string{server.URL}, server.Client())
+
+	t.Run("no trusted seal yet", func(t *testing.T) {
+
+		_, err := gossipClient.FetchSeal(context.Background(), server.URL)
+
+		assert.ErrorIs(t, err, ErrPeerSealUnavailable)
+	})
+
+	t.Run("trusted seal", func(t *testing.T) {
+
+		source.seal = []byte("seal")
+
+		seal, err := gossipClient.FetchSeal(context.Background(), server.URL+"/")
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []byte("seal"), seal)
+	})
+
+	t.Run("too large to be a seal", func(t *testing.T) {
+
+		source.seal = make([]byte, maxGossipSealBytes+1)
+
+		_, err := gossipClient.FetchSeal(context.Background(), server.URL)
+
+		assert.ErrorIs(t, err, ErrPeerSealTooLarge)
+	})
+
+	t.Run("not a get", func(t *testing.T) {
+
+		response, err := server.Client().Post(server.URL+GossipSealPath, gossipContentType, nil)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	})
+}
+
+// TestGossipClient_CompareViews tests peers whose views can not be compared are reported, not alerted
+func TestGossipClient_CompareViews(t *testing.T) {
+
+	// the peer serves a seal that is not signed by datatrails
+	server := httptest.NewServer(NewGossipHandler(&testSealSource{seal: []byte("not a seal")}))
+	defer server.Close()
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	gossipClient := NewGossipClient([]string{server.URL, unreachable.URL}, server.Client())
+
+	verifier := func(logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error) {
+		t.Fatal("no view should be compared")
+		return false, nil
+	}
+
+	peerViews := gossipClient.CompareViews(context.Background(), &massifs.MMRState{MMRSize: 7}, verifier)
+
+	require.Equal(t, 2, len(peerViews))
+
+	for _, peerView := range peerViews {
+		assert.NotEqual(t, nil, peerView.Err)
+		assert.Equal(t, "", peerView.Reason)
+		assert.Equal(t, false, peerView.Consistent())
+	}
+
+	assert.Equal(t, server.URL, peerViews[0].Peer)
+	assert.Equal(t, unreachable.URL, peerViews[1].Peer)
+}
+
+// TestCompareLogStates tests the older log state is always verified against the newer, whichever is ours
+func TestCompareLogStates(t *testing.T) {
+
+	ours := &massifs.MMRState{MMRSize: 7, Root: []byte("ours")}
+	newer := &massifs.MMRState{MMRSize: 10, Root: []byte("newer")}
+	older := &massifs.MMRState{MMRSize: 3, Root: []byte("older")}
+
+	verified := [][2]uint64{}
+	verifier := func(logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error) {
+		verified = append(verified, [2]uint64{logStateA.MMRSize, logStateB.MMRSize})
+		return logStateB != newer, nil
+	}
+
+	reason, err := CompareLogStates(ours, older, verifier)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", reason)
+
+	reason, err = CompareLogStates(ours, newer, verifier)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mmr size 10 is not consistent with mmr size 7", reason)
+
+	assert.Equal(t, [][2]uint64{{3, 7}, {7, 10}}, verified)
+
+	t.Run("split view", func(t *testing.T) {
+
+		// the same size, with a different root, is a split view of the log
+		reason, err := CompareLogStates(ours, &massifs.MMRState{MMRSize: 7, Root: []byte("theirs")}, verifier)
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "the root changed at mmr size 7", reason)
+	})
+}
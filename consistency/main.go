@@ -2,17 +2,34 @@ package main
 
 import (
 	"context"
-	"crypto/sha256"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/datatrails/go-datatrails-common/azblob"
-	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-demos/verification/witness"
 	"github.com/datatrails/go-datatrails-merklelog/massifs"
 )
 
-// ConsistencyDemo that a future log state is consistent with a previous signed log state.
-func ConsistencyDemo() (verified bool, err error) {
+var (
+	// trustedSealFile is the file of the signed log state trusted as the existing log state, the sample signed state if empty
+	trustedSealFile string
+)
+
+// ConsistencyDemo that a future log state, selected by the given new state, is consistent with a previous signed log state,
+// of the merklelog of the given settings, e.g. of one profile.
+//
+// If the log states are not consistent, an evidence package of the inconsistency is written as configured by the evidence options.
+// If the log states are consistent, the newer log state is co-signed as configured by the witness options,
+// which may also require the newer log state is co-signed by a quorum of third party witnesses.
+func ConsistencyDemo(ctx context.Context, settings *config.Settings, newState merklelog.NewStateSelector, evidenceOptions EvidenceOptions, witnessOptions WitnessOptions) (verified bool, err error) {
 
 	// First we need to get the existing signed log state from a trusted source, one we saved earlier.
 	//
@@ -23,7 +40,7 @@ func ConsistencyDemo() (verified bool, err error) {
 	//  1. gets the saved signed log state
 	//  2. verifies the signature of the signed log state
 	//  3. unmarshals the signed log state into a golang data structure.
-	existingLogState, err := ExistingSignedState()
+	existingLogState, err := merklelog.ExistingSignedState(ctx, settings.Value(trustedSealFlag), settings.VerificationKeyFile)
 	if err != nil {
 		return false, err
 	}
@@ -31,44 +48,53 @@ func ConsistencyDemo() (verified bool, err error) {
 	// Now we get a future log state and confirm that our earlier event continues to be consistently recorded.
 	//  the existing signed log state.
 	//
-	// The event we are basing the newer log state on is:
+	// By default the newer log state is the seal of the newest massif of the log, so consistency is proven up to now.
+	//  Otherwise it is the seal of the selected massif, e.g. the massif holding mmr index 830, from this event:
 	//   https://app.datatrails.ai/archivist/publicassets/fe022486-3272-4d44-aab5-765a37c17b85/events/3e7a16dd-01d6-44f5-870d-abb9c56d154b
 
 	// Get the signed state for the newer log state
-	hasher := sha256.New()
 	codec, err := massifs.NewRootSignerCodec()
 	if err != nil {
 		return false, err
 	}
 
-	reader, err := azblob.NewReaderNoAuth(url, azblob.WithContainer(container))
+	reader, err := merklelog.NewSettingsReader(ctx, settings)
 	if err != nil {
 		return false, err
 	}
 
-	massifIndex := massifs.MassifIndexFromMMRIndex(logverification.DefaultMassifHeight, newStateMMRIndex)
+	// The massifs read to find the newer log state are cached, so they are not read again to prove consistency
+	massifCache := merklelog.NewMassifCache(ctx, reader, settings.TenantID, uint8(settings.MassifHeight))
 
-	signedState, err := logverification.SignedLogState(
-		context.Background(), reader, hasher, codec, publicTenantID,
-		massifIndex,
-	)
+	signedState, err := merklelog.NewSignedState(ctx, reader, codec, massifCache, newState)
 	if err != nil {
 		return false, err
 	}
 
 	// Now verify the signed state using the datatrails seal verification key
-	verificationKey, err := VerificationKeyFromFile()
+	verificationKey, err := merklelog.VerificationKeyFromFile(settings.VerificationKeyFile)
 	if err != nil {
 		return false, err
 	}
 
-	err = signedState.VerifyWithPublicKey(verificationKey, nil)
+	err = merklelog.VerifySignature(ctx, signedState, verificationKey)
 	if err != nil {
 		return false, err
 	}
 
+	// The newer log state may also need to be co-signed by a quorum of third party witnesses, not just datatrails
+	if witnessOptions.Policy != nil {
+
+		witnessQuorum := witness.VerifyQuorum(witnessOptions.Policy, signedState, witnessOptions.CoSignatures)
+
+		err = witnessQuorum.Err()
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// unmarshal the signed log state into a golang data structure.
-	logState, err := logverification.LogState(signedState, codec)
+	logState, err := merklelog.DecodeLogState(ctx, signedState, codec)
 	if err != nil {
 		return false, err
 	}
@@ -78,23 +104,377 @@ func ConsistencyDemo() (verified bool, err error) {
 	// The first log state is taken from when an event for the breast cancer diagnosing AI model sample is on the log.
 	// The event can be found here: https://app.datatrails.ai/archivist/publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/9a192afe-9253-44d7-8585-c48f237f2134
 	//
-	// The second log state is taken from a later point in time when the above event, as well as the following event is on the log:
-	// https://app.datatrails.ai/archivist/publicassets/fe022486-3272-4d44-aab5-765a37c17b85/events/3e7a16dd-01d6-44f5-870d-abb9c56d154b
+	// The second log state is taken from a later point in time, by default now, when the above event is still on the log.
 	//
 	// We want to make sure that the second log state continues to include all the entries from the earlier log state, and includes them in exactly the same place
 
-	return logverification.VerifyConsistency(context.Background(), hasher, reader, publicTenantID, existingLogState, logState)
+	//
+	// The two log states may be many massifs apart, so the nodes of the consistency proof are read from whichever massif holds them.
+	store := merklelog.NewMassifStore(massifCache)
+
+	verified, err = merklelog.NewConsistencyVerifier(ctx, store)(existingLogState, logState)
+	if err != nil {
+		return false, err
+	}
+
+	// The newer log state is consistent, so we can witness it, co-signing it with our own witness key.
+	if verified && witnessOptions.Witness != nil {
+
+		coSignature, err := witnessOptions.Witness.CoSign(signedState)
+		if err != nil {
+			return false, err
+		}
+
+		_, err = witness.WriteCoSignature(witnessOptions.Dir, coSignature, logState.MMRSize)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if verified || evidenceOptions.Dir == "" {
+		return verified, nil
+	}
+
+	// The log states are not consistent, which is cryptographic proof of misbehaviour by the log.
+	//
+	// So capture the evidence: both signed log states, the massifs read, the peaks of the older log state and an explanation.
+	evidence := NewInconsistencyEvidence(store, settings.TenantID, existingLogState, logState, evidenceOptions.TrustedPeaksA)
+
+	signedStateA, err := merklelog.TrustedSeal(settings.Value(trustedSealFlag))
+	if err != nil {
+		return false, err
+	}
+
+	signedStateB, err := signedState.MarshalCBOR()
+	if err != nil {
+		return false, err
+	}
+
+	err = WriteEvidencePackage(evidenceOptions.Dir, evidence, signedStateA, signedStateB, massifCache.Massifs())
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// consistencyCheckFlags are the flags of the consistency check of the two log states,
+//
+//	registered again on a flag set of each profile checked.
+type consistencyCheckFlags struct {
+	newStateIndex  *int64
+	newStateMassif *int64
+	evidenceDir    *string
+	trustedProof   *string
+	witnessKey     *string
+	witnessID      *string
+	witnessDir     *string
+	witnessPolicy  *string
+	coSignatures   *string
+}
+
+// newConsistencyCheckFlags registers the flags of the consistency check on the given flag set
+func newConsistencyCheckFlags(fs *flag.FlagSet) consistencyCheckFlags {
 
+	return consistencyCheckFlags{
+		newStateIndex:  fs.Int64("new-state-index", -1, "mmr index whose massif seal is the new log state, defaults to the newest massif"),
+		newStateMassif: fs.Int64("new-state-massif", -1, "massif whose seal is the new log state, defaults to the newest massif"),
+		evidenceDir:    fs.String("evidence-dir", "consistency-evidence", "directory to write the evidence package to if the log states are not consistent, none is written if empty"),
+		trustedProof:   fs.String("trusted-proof", "", "consistency proof file, exported earlier, whose older log state peaks are trusted to find the diverged peaks"),
+		witnessKey:     fs.String("witness-key", "", "pem file of our witness private key, to co-sign the newer log state if consistent"),
+		witnessID:      fs.String("witness-id", "witness", "key id of our witness co-signatures"),
+		witnessDir:     fs.String("witness-dir", "witness-cosignatures", "directory to write our witness co-signatures to"),
+		witnessPolicy:  fs.String("witness-policy", "", "witness policy file of the quorum of witnesses that must co-sign the newer log state"),
+		coSignatures:   fs.String("cosignatures", "", "comma separated co-signature files, or directories of them, of the witnesses of the witness policy"),
+	}
 }
 
+// options gets the new state, evidence options and witness options of the consistency check from the flags,
+//
+//	of the merklelog of the given settings.
+func (cf consistencyCheckFlags) options(ctx context.Context, settings *config.Settings) (merklelog.NewStateSelector, EvidenceOptions, WitnessOptions, error) {
+
+	newState := merklelog.NewStateSelector{}
+	if *cf.newStateIndex >= 0 {
+		mmrIndex := uint64(*cf.newStateIndex)
+		newState.MMRIndex = &mmrIndex
+	}
+
+	if *cf.newStateMassif >= 0 {
+		massifIndex := uint64(*cf.newStateMassif)
+		newState.MassifIndex = &massifIndex
+	}
+
+	var err error
+
+	evidenceOptions := EvidenceOptions{Dir: *cf.evidenceDir}
+	if *cf.trustedProof != "" {
+
+		evidenceOptions.TrustedPeaksA, err = trustedPeaksFromFile(ctx, settings, *cf.trustedProof)
+		if err != nil {
+			return merklelog.NewStateSelector{}, EvidenceOptions{}, WitnessOptions{}, fmt.Errorf("failed to read the trusted peaks of the older log state: %w", err)
+		}
+	}
+
+	witnessOptions := WitnessOptions{Dir: *cf.witnessDir}
+	if *cf.witnessKey != "" {
+
+		witnessOptions.Witness, err = witness.FromFile(*cf.witnessID, *cf.witnessKey)
+		if err != nil {
+			return merklelog.NewStateSelector{}, EvidenceOptions{}, WitnessOptions{}, fmt.Errorf("failed to read the witness key: %w", err)
+		}
+	}
+
+	if *cf.witnessPolicy != "" {
+
+		witnessOptions.Policy, witnessOptions.CoSignatures, err = witness.ReadQuorumFiles(*cf.witnessPolicy, config.SplitList(*cf.coSignatures))
+		if err != nil {
+			return merklelog.NewStateSelector{}, EvidenceOptions{}, WitnessOptions{}, fmt.Errorf("failed to read the witness policy and co-signatures: %w", err)
+		}
+	}
+
+	return newState, evidenceOptions, witnessOptions, nil
+}
+
+// Demo of the consistency of a future log state with a previous signed log state
+//
+// Optionally the consistency proof between the two log states is exported as a portable file,
+// or a previously exported consistency proof file is re-verified offline with the two signed log states,
+// or a directory of archived signed log states is verified as a chain of consistent log states,
+// or the log of every profile of the config file is checked, with a combined report.
 func main() {
 
-	verified, err := ConsistencyDemo()
+	exportProof := flag.String("export-proof", "", "file to export the consistency proof between the two log states to")
+	verifyProof := flag.String("verify-proof", "", "consistency proof file to re-verify offline with the two signed log states")
+	sealA := flag.String("seal-a", "", "file of the older signed log state, written on -export-proof, read on -verify-proof")
+	sealB := flag.String("seal-b", "", "file of the newer signed log state, written on -export-proof, read on -verify-proof")
+	sealDir := flag.String("seal-dir", "", "directory of archived signed log states, .cbor files, to verify as a chain, in file name order")
+	flag.StringVar(&trustedSealFile, trustedSealFlag, "", "file of the signed log state trusted as the existing log state, e.g. saved earlier, defaults to a sample signed log state of the public tenant")
+	checkFlags := newConsistencyCheckFlags(flag.CommandLine)
+	monitor := flag.Bool("monitor", false, "continuously verify the newest seal is consistent with the latest trusted seal, until interrupted")
+	monitorInterval := flag.Duration("monitor-interval", defaultMonitorInterval, "interval between checks of the newest seal in -monitor mode")
+	gossipListen := flag.String("gossip-listen", "", "address to serve our latest trusted seal to peers on in -monitor mode, e.g. :8080")
+	gossipPeers := flag.String("gossip-peers", "", "comma separated base urls of peers to exchange latest trusted seals with in -monitor mode")
+	metricsListen := flag.String("metrics-listen", "", "address to serve prometheus metrics on /metrics in -monitor mode, e.g. :9090")
+	allProfiles := flag.Bool("all-profiles", false, "check the consistency of the log of every profile of the config file, with a combined report")
+	profilesReport := flag.String("profiles-report", "", "file to write the combined json report of -all-profiles to")
+	config.Flags(flag.CommandLine)
+	flag.Parse()
+
+	configFile, err := config.Load(flag.CommandLine, "consistency")
+	if err != nil {
+		fmt.Printf("Failed to load the config: %v\n", err)
+		os.Exit(1)
+	}
 
+	err = logging.Setup(config.LogLevel, config.LogFormat)
 	if err != nil {
-		fmt.Printf("Failed to verify the consistency of the two log states: %v", err)
+		fmt.Printf("Failed to set up logging: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Two log state verification consistency is: %v\n", verified)
+	// the massif readers log to the datatrails logger, which is set up once here, discarding its logs
+	logger.New("NOOP")
+
+	ctx, err := tracing.Setup(context.Background(), "consistency", config.TraceExporter)
+	if err != nil {
+		slog.Error("failed to set up tracing", logging.KeyError, err)
+		os.Exit(1)
+	}
+	defer tracing.Flush()
+
+	if *allProfiles {
+
+		// each profile is checked with its own settings, and its own flags of the consistency check
+		check := func(ctx context.Context, settings *config.Settings) (bool, error) {
+
+			profileFlags := flag.NewFlagSet("profile", flag.ContinueOnError)
+			profileCheckFlags := newConsistencyCheckFlags(profileFlags)
+
+			err := settings.Set(profileFlags)
+			if err != nil {
+				return false, err
+			}
+
+			newState, evidenceOptions, witnessOptions, err := profileCheckFlags.options(ctx, settings)
+			if err != nil {
+				return false, err
+			}
+
+			return ConsistencyDemo(ctx, settings, newState, evidenceOptions, witnessOptions)
+		}
+
+		report, err := ProfilesDemo(ctx, configFile, check)
+		if err != nil {
+			slog.Error("failed to check the consistency of the log of every profile", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		logProfilesReport(report)
+
+		if *profilesReport != "" {
+
+			err = WriteProfilesReport(report, *profilesReport)
+			if err != nil {
+				slog.Error("failed to write the combined report of every profile", logging.KeyError, err)
+				tracing.Exit(1)
+			}
+		}
+
+		if !report.Consistent() {
+			tracing.Exit(1)
+		}
+
+		return
+	}
+
+	if *monitor {
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		monitorOptions := MonitorOptions{
+			Interval:      *monitorInterval,
+			GossipListen:  *gossipListen,
+			GossipPeers:   config.SplitList(*gossipPeers),
+			MetricsListen: *metricsListen,
+		}
+
+		err = MonitorDemo(ctx, monitorOptions)
+		if err != nil {
+			slog.Error("failed to monitor the consistency of the log", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		return
+	}
+
+	if *sealDir != "" {
+
+		sealChain, err := SealChainDemo(ctx, *sealDir)
+		if err != nil {
+			slog.Error("failed to verify the chain of signed log states", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		slog.Info("verified the chain of signed log states", "verified_links", sealChain.VerifiedLinks, "seals", len(sealChain.Seals))
+
+		if !sealChain.Consistent() {
+			slog.Error("chain of signed log states breaks",
+				"previous_seal", sealChain.Break.Previous, "seal", sealChain.Break.Seal, "reason", sealChain.Break.Reason)
+			tracing.Exit(1)
+		}
+
+		return
+	}
+
+	if *verifyProof != "" {
+
+		if *sealA == "" || *sealB == "" {
+			slog.Error("failed to verify the consistency proof file: -seal-a and -seal-b are required")
+			tracing.Exit(1)
+		}
+
+		verified, err := verifyConsistencyProofFiles(ctx, *verifyProof, *sealA, *sealB)
+		if err != nil {
+			slog.Error("failed to verify the consistency proof file", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		slog.Info("consistency proof file verification", "proof", *verifyProof, "verified", verified)
+
+		if !verified {
+			tracing.Exit(1)
+		}
+
+		return
+	}
+
+	settings, err := configFile.Settings(configFile.Profile())
+	if err != nil {
+		slog.Error("failed to read the settings of the consistency check", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	newState, evidenceOptions, witnessOptions, err := checkFlags.options(ctx, settings)
+	if err != nil {
+		slog.Error("failed to read the options of the consistency check", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	verified, err := ConsistencyDemo(ctx, settings, newState, evidenceOptions, witnessOptions)
+
+	if err != nil {
+		slog.Error("failed to verify the consistency of the two log states", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	slog.Info("two log state verification", logging.KeyTenant, settings.TenantID, "consistent", verified)
+
+	if !verified {
+
+		if evidenceOptions.Dir != "" {
+			slog.Error("evidence of the inconsistency written", "evidence_dir", evidenceOptions.Dir)
+		}
+
+		tracing.Exit(1)
+	}
+
+	if witnessOptions.Witness != nil {
+		slog.Info("newer log state co-signed", "witness", witnessOptions.Witness.ID, "witness_dir", witnessOptions.Dir)
+	}
+
+	if *exportProof != "" {
+
+		evidence, err := ConsistencyEvidenceDemo(ctx, newState)
+		if err != nil {
+			slog.Error("failed to create the consistency proof of the two log states", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		err = WriteConsistencyEvidence(evidence, *exportProof, *sealA, *sealB)
+		if err != nil {
+			slog.Error("failed to export the consistency proof of the two log states", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		slog.Info("consistency proof exported", "proof", *exportProof)
+	}
+}
+
+// verifyConsistencyProofFiles reads the consistency proof file and the two signed log states, then verifies them offline
+func verifyConsistencyProofFiles(ctx context.Context, proofPath string, signedStateAPath string, signedStateBPath string) (bool, error) {
+
+	proofJson, err := os.ReadFile(proofPath)
+	if err != nil {
+		return false, err
+	}
+
+	signedStateA, err := os.ReadFile(signedStateAPath)
+	if err != nil {
+		return false, err
+	}
+
+	signedStateB, err := os.ReadFile(signedStateBPath)
+	if err != nil {
+		return false, err
+	}
+
+	return VerifyConsistencyProofFile(ctx, proofJson, signedStateA, signedStateB)
+}
+
+// trustedPeaksFromFile reads the trusted peaks of the existing signed log state, of the given settings, from a consistency proof file
+func trustedPeaksFromFile(ctx context.Context, settings *config.Settings, proofPath string) ([][]byte, error) {
+
+	proofJson, err := os.ReadFile(proofPath)
+	if err != nil {
+		return nil, err
+	}
+
+	existingLogState, err := merklelog.ExistingSignedState(ctx, settings.Value(trustedSealFlag), settings.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return TrustedPeaksFromProofFile(proofJson, existingLogState.MMRSize)
 }
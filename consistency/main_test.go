@@ -1,14 +1,39 @@
 package main
 
 import (
+	"context"
+	"os"
 	"testing"
 
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain sets up the datatrails logger the massif readers log to, as main does
+func TestMain(m *testing.M) {
+
+	logger.New("NOOP")
+
+	os.Exit(m.Run())
+}
+
 func TestConsistencyDemo(t *testing.T) {
 
-	verified, err := ConsistencyDemo()
+	verified, err := ConsistencyDemo(context.Background(), config.Current(), merklelog.NewStateSelector{}, EvidenceOptions{}, WitnessOptions{})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, verified)
+
+}
+
+// TestConsistencyDemo_SampleNewState tests consistency up to the new state at the time the demo was written
+func TestConsistencyDemo_SampleNewState(t *testing.T) {
+
+	mmrIndex := sampleNewStateMMRIndex
+
+	verified, err := ConsistencyDemo(context.Background(), config.Current(), merklelog.NewStateSelector{MMRIndex: &mmrIndex}, EvidenceOptions{}, WitnessOptions{})
 
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, verified)
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/**
+ * Monitor continuously verifies the newest seal of the tenant's merklelog is consistent with
+ *  the latest seal it trusts, starting from the existing signed log state.
+ *
+ * Each time the newest seal is verified consistent it becomes the latest trusted seal, so the
+ *  monitor proves the log has only ever been appended to for as long as it runs.
+ *
+ * The latest trusted seal is served to peers, and the peers' latest trusted seals are verified
+ *  consistent with ours, see gossip.
+ */
+
+const (
+	// defaultMonitorInterval is the default interval between checks of the newest seal
+	defaultMonitorInterval = time.Minute
+
+	// types of verification of the monitor metrics
+	verificationConsistency = "consistency"
+	verificationGossip      = "gossip"
+)
+
+// MonitorOptions configure the monitor
+type MonitorOptions struct {
+
+	// Interval between checks of the newest seal
+	Interval time.Duration
+
+	// GossipListen is the address to serve our latest trusted seal to peers on, not served if empty
+	GossipListen string
+
+	// GossipPeers are the base urls of the peers to exchange latest trusted seals with
+	GossipPeers []string
+
+	// MetricsListen is the address to serve the metrics on, not served if empty
+	MetricsListen string
+}
+
+// MonitorCheck is the result of checking the newest seal against the latest trusted seal
+type MonitorCheck struct {
+
+	// LogState is the log state of the newest seal
+	LogState *massifs.MMRState
+
+	// Reason the newest seal is not consistent with the latest trusted seal, empty if consistent
+	Reason string
+}
+
+// Consistent is true if the newest seal is consistent with the latest trusted seal
+func (mc MonitorCheck) Consistent() bool {
+	return mc.Reason == ""
+}
+
+// Monitor verifies the newest seals of the tenant's merklelog, keeping the latest trusted seal
+type Monitor struct {
+	reader azblob.Reader
+	codec  massifs.RootSignerCodec
+
+	mu           sync.RWMutex
+	trustedSeal  []byte
+	trustedState *massifs.MMRState
+}
+
+// NewMonitor creates a monitor trusting the given seal, verified with the datatrails seal verification key
+func NewMonitor(ctx context.Context, reader azblob.Reader, trustedSeal []byte) (*Monitor, error) {
+
+	trustedState, err := verifiedLogState(ctx, trustedSeal)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := massifs.NewRootSignerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Monitor{
+		reader:       reader,
+		codec:        codec,
+		trustedSeal:  trustedSeal,
+		trustedState: trustedState,
+	}, nil
+}
+
+// LatestTrustedSeal gets the latest trusted seal, and its log state
+func (m *Monitor) LatestTrustedSeal() ([]byte, *massifs.MMRState) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.trustedSeal, m.trustedState
+}
+
+// Check the newest seal is consistent with the latest trusted seal,
+//
+//	if it is, the newest seal becomes the latest trusted seal.
+func (m *Monitor) Check(ctx context.Context) (*MonitorCheck, error) {
+
+	// the newest massif is still growing, so the massifs are read afresh for every check
+	massifCache := merklelog.NewMassifCache(ctx, m.reader, config.TenantID, uint8(config.MassifHeight))
+
+	signedState, err := merklelog.NewSignedState(ctx, m.reader, m.codec, massifCache, merklelog.NewStateSelector{})
+	if err != nil {
+		return nil, err
+	}
+
+	seal, err := signedState.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+
+	logState, err := verifiedLogState(ctx, seal)
+	if err != nil {
+		return nil, err
+	}
+
+	_, trustedState := m.LatestTrustedSeal()
+
+	reason, err := sealChainLink(trustedState, logState, merklelog.NewConsistencyVerifier(ctx, merklelog.NewMassifStore(massifCache)))
+	if err != nil {
+		return nil, err
+	}
+
+	check := &MonitorCheck{LogState: logState, Reason: reason}
+
+	if check.Consistent() {
+
+		m.mu.Lock()
+		m.trustedSeal, m.trustedState = seal, logState
+		m.mu.Unlock()
+	}
+
+	return check, nil
+}
+
+// verifier verifies consistency between log states, reading the massifs afresh
+func (m *Monitor) verifier(ctx context.Context) merklelog.ConsistencyVerifier {
+
+	store := merklelog.NewMassifStore(merklelog.NewMassifCache(ctx, m.reader, config.TenantID, uint8(config.MassifHeight)))
+
+	return merklelog.NewConsistencyVerifier(ctx, store)
+}
+
+// MonitorDemo runs the monitor until the given context is done, checking the newest seal,
+//
+//	then exchanging latest trusted seals with the peers, every interval.
+func MonitorDemo(ctx context.Context, options MonitorOptions) error {
+
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	trustedSeal, err := merklelog.TrustedSeal(trustedSealFile)
+	if err != nil {
+		return err
+	}
+
+	monitor, err := NewMonitor(ctx, reader, trustedSeal)
+	if err != nil {
+		return err
+	}
+
+	if options.GossipListen != "" {
+
+		server := &http.Server{
+			Addr:              options.GossipListen,
+			Handler:           NewGossipHandler(monitor),
+			ReadHeaderTimeout: gossipReadHeaderTimeout,
+		}
+
+		go func() {
+			err := server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("failed to serve the latest trusted seal to peers", logging.KeyError, err)
+			}
+		}()
+
+		defer server.Close()
+	}
+
+	if options.MetricsListen != "" {
+
+		go func() {
+			err := metrics.Serve(ctx, options.MetricsListen)
+			if err != nil {
+				slog.Error("failed to serve the metrics", logging.KeyError, err)
+			}
+		}()
+	}
+
+	gossipClient := NewGossipClient(options.GossipPeers, &http.Client{Timeout: gossipTimeout})
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		monitorRound(ctx, monitor, gossipClient)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// monitorRound checks the newest seal, then exchanges latest trusted seals with the peers,
+//
+//	printing an alert for any inconsistency.
+func monitorRound(ctx context.Context, monitor *Monitor, gossipClient *GossipClient) {
+
+	// each round is a trace of its own, rather than a child of the span of the whole run
+	ctx, span := tracing.Tracer.Start(ctx, "monitor.round", trace.WithNewRoot())
+	defer span.End()
+
+	check, err := monitor.Check(ctx)
+	metrics.Verification(verificationConsistency, err == nil && check.Consistent(), err)
+
+	switch {
+	case err != nil:
+		slog.Error("failed to check the newest seal", logging.KeyTenant, config.TenantID, logging.KeyError, err)
+	case !check.Consistent():
+		slog.Error("ALERT: the newest seal is not consistent with the latest trusted seal", logging.KeyTenant, config.TenantID, logging.KeyMMRSize, check.LogState.MMRSize, "reason", check.Reason)
+	default:
+		metrics.LatestSeal(check.LogState)
+		slog.Info("newest seal is consistent with the latest trusted seal", logging.KeyTenant, config.TenantID, logging.KeyMMRSize, check.LogState.MMRSize)
+	}
+
+	_, trustedState := monitor.LatestTrustedSeal()
+
+	for _, peerView := range gossipClient.CompareViews(ctx, trustedState, monitor.verifier(ctx)) {
+
+		metrics.Verification(verificationGossip, peerView.Consistent(), peerView.Err)
+
+		switch {
+		case peerView.Err != nil:
+			slog.Warn("failed to compare the view of peer", "peer", peerView.Peer, logging.KeyError, peerView.Err)
+		case !peerView.Consistent():
+			slog.Error("ALERT: the view of peer is inconsistent with ours", "peer", peerView.Peer, logging.KeyMMRSize, peerView.LogState.MMRSize, "reason", peerView.Reason)
+		default:
+			slog.Info("view of peer is consistent with ours", "peer", peerView.Peer, logging.KeyMMRSize, peerView.LogState.MMRSize)
+		}
+	}
+}
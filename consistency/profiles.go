@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+/**
+ * Profiles checks the consistency of the log of every profile of the config file in one go,
+ *  e.g. of the public tenant and each private tenant verified, with a combined report.
+ *
+ * Each profile is checked with its own tenant, blob storage, credentials, keys and state directories.
+ *  A profile that fails to load, or to check, is reported, and the next profile is still checked.
+ */
+
+var (
+	ErrNoProfiles = errors.New("no profiles in the config file")
+)
+
+// ProfileResult is the result of the consistency check of the log of one profile
+type ProfileResult struct {
+	Profile    string `json:"profile"`
+	Tenant     string `json:"tenant,omitempty"`
+	Consistent bool   `json:"consistent"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ProfilesReport is the combined report of the consistency checks of every profile, in profile name order
+type ProfilesReport struct {
+	Results []ProfileResult `json:"results"`
+}
+
+// Consistent is true if the log of every profile was checked, and is consistent
+func (pr ProfilesReport) Consistent() bool {
+
+	for _, result := range pr.Results {
+		if result.Error != "" || !result.Consistent {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ProfileCheck checks the consistency of the log of a profile, with the given settings of the profile
+type ProfileCheck func(ctx context.Context, settings *config.Settings) (bool, error)
+
+// ProfilesDemo gets the settings of every profile of the given config in turn, checking the consistency of its log with the given check
+func ProfilesDemo(ctx context.Context, configFile *config.Config, check ProfileCheck) (*ProfilesReport, error) {
+
+	profiles := configFile.Profiles()
+	if len(profiles) == 0 {
+		return nil, ErrNoProfiles
+	}
+
+	report := &ProfilesReport{Results: make([]ProfileResult, 0, len(profiles))}
+	for _, profile := range profiles {
+		report.Results = append(report.Results, profileResult(ctx, configFile, profile, check))
+	}
+
+	return report, nil
+}
+
+// profileResult gets the settings of the given profile, then checks the consistency of its log with them,
+//
+//	leaving the flags and the shared settings as they are.
+func profileResult(ctx context.Context, configFile *config.Config, profile string, check ProfileCheck) ProfileResult {
+
+	ctx, span := tracing.StartSpan(ctx, "profile", attribute.String(logging.KeyProfile, profile))
+
+	result := ProfileResult{Profile: profile}
+
+	settings, err := configFile.Settings(profile)
+	if err != nil {
+		tracing.EndSpan(span, err)
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Tenant = settings.TenantID
+
+	result.Consistent, err = check(ctx, settings)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// logProfilesReport logs the result of every profile of the report
+func logProfilesReport(report *ProfilesReport) {
+
+	for _, result := range report.Results {
+
+		switch {
+		case result.Error != "":
+			slog.Error("failed to check the consistency of the log of profile", logging.KeyProfile, result.Profile, logging.KeyTenant, result.Tenant, logging.KeyError, result.Error)
+		case !result.Consistent:
+			slog.Error("ALERT: the log of profile is not consistent", logging.KeyProfile, result.Profile, logging.KeyTenant, result.Tenant)
+		default:
+			slog.Info("the log of profile is consistent", logging.KeyProfile, result.Profile, logging.KeyTenant, result.Tenant)
+		}
+	}
+}
+
+// WriteProfilesReport writes the combined report of every profile to the given file as json
+func WriteProfilesReport(report *ProfilesReport, reportPath string) error {
+
+	reportJson, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(reportPath, reportJson, proofFilePerm)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfigFlags registers the shared settings, and the trusted seal, on a new flag set
+func testConfigFlags(t *testing.T) *flag.FlagSet {
+
+	fs := flag.NewFlagSet("consistency", flag.ContinueOnError)
+	config.Flags(fs)
+	fs.String(trustedSealFlag, "", "file of the signed log state trusted as the existing log state")
+
+	return fs
+}
+
+// writeConfig writes the given YAML config file to a temporary directory
+func writeConfig(t *testing.T, configYaml string) string {
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configYaml), 0o644))
+
+	return configPath
+}
+
+// TestProfilesDemo tests every profile is checked with its own settings, in profile name order,
+//
+//	and a profile that fails to load, or to check, does not stop the other profiles being checked.
+func TestProfilesDemo(t *testing.T) {
+
+	configPath := writeConfig(t, `
+profiles:
+  public: {}
+  private-a:
+    tenant: tenant/private-a
+    consistency:
+      trusted-seal: private-a.cbor
+  private-b:
+    tenant: tenant/private-b
+  broken:
+    tenant: private-c
+`)
+
+	fs := testConfigFlags(t)
+	require.NoError(t, fs.Parse([]string{"-config", configPath}))
+
+	configFile, err := config.Load(fs, "consistency")
+	require.NoError(t, err)
+
+	checked := []string{}
+	trustedSeals := []string{}
+	check := func(ctx context.Context, settings *config.Settings) (bool, error) {
+
+		checked = append(checked, settings.TenantID)
+		trustedSeals = append(trustedSeals, settings.Value(trustedSealFlag))
+
+		switch settings.TenantID {
+		case "tenant/private-a":
+			return false, nil
+		case "tenant/private-b":
+			return false, errors.New("blob storage unavailable")
+		default:
+			return true, nil
+		}
+	}
+
+	report, err := ProfilesDemo(context.Background(), configFile, check)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"tenant/private-a", "tenant/private-b", config.DefaultTenantID}, checked)
+	assert.Equal(t, []string{"private-a.cbor", "", ""}, trustedSeals)
+
+	// the profiles are checked without changing the shared settings
+	assert.Equal(t, config.DefaultTenantID, config.TenantID)
+	assert.False(t, report.Consistent())
+
+	require.Len(t, report.Results, 4)
+
+	assert.Equal(t, "broken", report.Results[0].Profile)
+	assert.Contains(t, report.Results[0].Error, "tenant")
+
+	assert.Equal(t, ProfileResult{Profile: "private-a", Tenant: "tenant/private-a"}, report.Results[1])
+	assert.Equal(t, ProfileResult{Profile: "private-b", Tenant: "tenant/private-b", Error: "blob storage unavailable"}, report.Results[2])
+	assert.Equal(t, ProfileResult{Profile: "public", Tenant: config.DefaultTenantID, Consistent: true}, report.Results[3])
+
+	t.Run("report written as json", func(t *testing.T) {
+
+		reportPath := filepath.Join(t.TempDir(), "report.json")
+		require.NoError(t, WriteProfilesReport(report, reportPath))
+
+		reportJson, err := os.ReadFile(reportPath)
+		require.NoError(t, err)
+
+		written := ProfilesReport{}
+		require.NoError(t, json.Unmarshal(reportJson, &written))
+		assert.Equal(t, *report, written)
+	})
+}
+
+// TestProfilesDemo_NoProfiles tests there must be profiles to check
+func TestProfilesDemo_NoProfiles(t *testing.T) {
+
+	fs := testConfigFlags(t)
+	require.NoError(t, fs.Parse(nil))
+
+	configFile, err := config.Load(fs, "consistency")
+	require.NoError(t, err)
+
+	_, err = ProfilesDemo(context.Background(), configFile, nil)
+	assert.ErrorIs(t, err, ErrNoProfiles)
+}
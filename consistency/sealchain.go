@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * Seal chain verifies a directory of archived signed log states (seals), e.g. daily snapshots,
+ *  proving the merklelog has only ever been appended to over the whole archive.
+ *
+ * The seals are the .cbor files of the archive, other files are ignored. The seals are ordered
+ *  by file name, so archives named by date are verified in date order.
+ *  Each adjacent pair of seals is then verified:
+ *  1. both seals are signed with the datatrails seal verification key.
+ *  2. the newer log state is not smaller than the older log state.
+ *  3. the newer log state is consistent with the older log state.
+ *
+ * The first pair of seals that fails is the break in the chain.
+ */
+
+const (
+	// sealFileExtension is the extension of the files of the seals in an archive of seals
+	sealFileExtension = ".cbor"
+)
+
+// ArchivedSeal is a signed log state read from an archive of seals
+type ArchivedSeal struct {
+
+	// Name is the file name of the seal in the archive
+	Name string
+
+	// LogState is the verified log state of the seal, nil if the seal failed verification
+	LogState *massifs.MMRState
+
+	// Invalid is the reason the seal failed verification, empty if the seal is verified
+	Invalid string
+}
+
+// SealChainBreak is the first pair of adjacent seals in the archive that are not consistent
+type SealChainBreak struct {
+
+	// Previous is the name of the last seal consistent with the chain, empty if the first seal breaks the chain
+	Previous string
+
+	// Seal is the name of the first seal that breaks the chain
+	Seal string
+
+	// Reason the seal breaks the chain
+	Reason string
+}
+
+// SealChain is the result of verifying a chain of archived seals
+type SealChain struct {
+
+	// Seals are the names of the seals, in chain order
+	Seals []string
+
+	// VerifiedLinks is the number of adjacent pairs of seals verified consistent before any break
+	VerifiedLinks int
+
+	// Break is the first break in the chain, nil if the whole chain is consistent
+	Break *SealChainBreak
+}
+
+// Consistent is true if every adjacent pair of seals in the chain is consistent
+func (sc SealChain) Consistent() bool {
+	return sc.Break == nil
+}
+
+// SealChainDemo verifies the chain of archived seals in the given directory
+//
+//	are consistent with each other, in file name order.
+func SealChainDemo(ctx context.Context, sealDir string) (*SealChain, error) {
+
+	seals, err := ReadSealDir(ctx, sealDir)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the seals may span many massifs, the massifs read are shared by every link of the chain
+	store := merklelog.NewMassifStore(merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)))
+
+	return VerifySealChain(seals, merklelog.NewConsistencyVerifier(ctx, store))
+}
+
+// ReadSealDir reads the archived seals, the .cbor files, in the given directory, in file name order,
+//
+//	verifying each with the datatrails seal verification key.
+func ReadSealDir(ctx context.Context, sealDir string) ([]ArchivedSeal, error) {
+
+	dirEntries, err := os.ReadDir(sealDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, dirEntry := range dirEntries {
+		if dirEntry.Type().IsRegular() && filepath.Ext(dirEntry.Name()) == sealFileExtension {
+			names = append(names, dirEntry.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	seals := make([]ArchivedSeal, 0, len(names))
+	for _, name := range names {
+
+		signedState, err := os.ReadFile(filepath.Join(sealDir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		seal := ArchivedSeal{Name: name}
+
+		seal.LogState, err = verifiedLogState(ctx, signedState)
+		if err != nil {
+			seal.Invalid = err.Error()
+		}
+
+		seals = append(seals, seal)
+	}
+
+	return seals, nil
+}
+
+// VerifySealChain verifies each adjacent pair of the given seals, in order,
+//
+//	stopping at the first break in the chain.
+func VerifySealChain(seals []ArchivedSeal, verifier merklelog.ConsistencyVerifier) (*SealChain, error) {
+
+	sealChain := &SealChain{}
+	for _, seal := range seals {
+		sealChain.Seals = append(sealChain.Seals, seal.Name)
+	}
+
+	for index, seal := range seals {
+
+		previous := ""
+		if index > 0 {
+			previous = seals[index-1].Name
+		}
+
+		if seal.Invalid != "" {
+			sealChain.Break = &SealChainBreak{Previous: previous, Seal: seal.Name, Reason: fmt.Sprintf("invalid seal: %s", seal.Invalid)}
+			return sealChain, nil
+		}
+
+		if index == 0 {
+			continue
+		}
+
+		reason, err := sealChainLink(seals[index-1].LogState, seal.LogState, verifier)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason != "" {
+			sealChain.Break = &SealChainBreak{Previous: previous, Seal: seal.Name, Reason: reason}
+			return sealChain, nil
+		}
+
+		sealChain.VerifiedLinks++
+	}
+
+	return sealChain, nil
+}
+
+// sealChainLink verifies the newer log state is consistent with the older log state,
+//
+//	returning the reason the link breaks the chain, or empty if consistent.
+func sealChainLink(logStateA *massifs.MMRState, logStateB *massifs.MMRState, verifier merklelog.ConsistencyVerifier) (string, error) {
+
+	if logStateB.MMRSize < logStateA.MMRSize {
+		return fmt.Sprintf("the log shrank from mmr size %d to %d", logStateA.MMRSize, logStateB.MMRSize), nil
+	}
+
+	// the log has not grown, so the root must not have changed
+	if logStateB.MMRSize == logStateA.MMRSize {
+
+		if !bytes.Equal(logStateA.Root, logStateB.Root) {
+			return fmt.Sprintf("the root changed at mmr size %d", logStateA.MMRSize), nil
+		}
+
+		return "", nil
+	}
+
+	consistent, err := verifier(logStateA, logStateB)
+	if err != nil {
+		return "", err
+	}
+
+	if !consistent {
+		return fmt.Sprintf("mmr size %d is not consistent with mmr size %d", logStateB.MMRSize, logStateA.MMRSize), nil
+	}
+
+	return "", nil
+}
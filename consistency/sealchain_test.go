@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifySealChain tests the first break in a chain of seals is found
+func TestVerifySealChain(t *testing.T) {
+
+	seal := func(name string, mmrSize uint64, root string) ArchivedSeal {
+		return ArchivedSeal{Name: name, LogState: &massifs.MMRState{MMRSize: mmrSize, Root: []byte(root)}}
+	}
+
+	// the fake verifier finds mmr size 26 inconsistent with any older log state
+	verifier := func(logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error) {
+		return logStateB.MMRSize != 26, nil
+	}
+
+	tests := []struct {
+		name          string
+		seals         []ArchivedSeal
+		verifiedLinks int
+		expected      *SealChainBreak
+	}{
+		{
+			name:          "consistent",
+			seals:         []ArchivedSeal{seal("day1", 7, "a"), seal("day2", 7, "a"), seal("day3", 11, "b"), seal("day4", 19, "c")},
+			verifiedLinks: 3,
+		},
+		{
+			name:          "shrank",
+			seals:         []ArchivedSeal{seal("day1", 7, "a"), seal("day2", 11, "b"), seal("day3", 10, "c")},
+			verifiedLinks: 1,
+			expected:      &SealChainBreak{Previous: "day2", Seal: "day3", Reason: "the log shrank from mmr size 11 to 10"},
+		},
+		{
+			name:          "root changed",
+			seals:         []ArchivedSeal{seal("day1", 7, "a"), seal("day2", 7, "b")},
+			verifiedLinks: 0,
+			expected:      &SealChainBreak{Previous: "day1", Seal: "day2", Reason: "the root changed at mmr size 7"},
+		},
+		{
+			name:          "inconsistent",
+			seals:         []ArchivedSeal{seal("day1", 7, "a"), seal("day2", 11, "b"), seal("day3", 26, "c"), seal("day4", 32, "d")},
+			verifiedLinks: 1,
+			expected:      &SealChainBreak{Previous: "day2", Seal: "day3", Reason: "mmr size 26 is not consistent with mmr size 11"},
+		},
+		{
+			name:          "invalid seal",
+			seals:         []ArchivedSeal{{Name: "day1", Invalid: "bad signature"}, seal("day2", 7, "a")},
+			verifiedLinks: 0,
+			expected:      &SealChainBreak{Previous: "", Seal: "day1", Reason: "invalid seal: bad signature"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			sealChain, err := VerifySealChain(test.seals, verifier)
+			require.NoError(t, err)
+
+			assert.Equal(t, len(test.seals), len(sealChain.Seals))
+			assert.Equal(t, test.verifiedLinks, sealChain.VerifiedLinks)
+			assert.Equal(t, test.expected, sealChain.Break)
+			assert.Equal(t, test.expected == nil, sealChain.Consistent())
+		})
+	}
+}
+
+// TestSealChainDemo tests a directory of archived seals, the existing signed log state
+//
+//	followed by the newer signed log state, is a consistent chain.
+func TestSealChainDemo(t *testing.T) {
+
+	reader, err := azblob.NewReaderNoAuth(config.URL, azblob.WithContainer(config.Container))
+	require.NoError(t, err)
+
+	codec, err := massifs.NewRootSignerCodec()
+	require.NoError(t, err)
+
+	massifHeight, err := merklelog.DiscoverMassifHeight(context.Background(), reader, config.TenantID)
+	require.NoError(t, err)
+
+	massifIndex := massifs.MassifIndexFromMMRIndex(massifHeight, sampleNewStateMMRIndex)
+
+	signedState, err := logverification.SignedLogState(context.Background(), reader, sha256.New(), codec, config.TenantID, massifIndex)
+	require.NoError(t, err)
+
+	newerSignedState, err := signedState.MarshalCBOR()
+	require.NoError(t, err)
+
+	sealDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sealDir, "2024-06-01.cbor"), merklelog.SampleSignedStateCbor, proofFilePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(sealDir, "2024-06-02.cbor"), newerSignedState, proofFilePerm))
+
+	// files other than seals in the archive are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(sealDir, "README.md"), []byte("daily seals"), proofFilePerm))
+
+	sealChain, err := SealChainDemo(context.Background(), sealDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"2024-06-01.cbor", "2024-06-02.cbor"}, sealChain.Seals)
+	assert.Equal(t, 1, sealChain.VerifiedLinks)
+	assert.Equal(t, true, sealChain.Consistent())
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// syntheticLeafCount is the number of leaves in the synthetic log
+	syntheticLeafCount = 32
+)
+
+// syntheticLog is an in memory merklelog, for the evidence of logs that are not consistent
+type syntheticLog struct {
+	nodes [][]byte
+}
+
+// newSyntheticLog creates a synthetic log with the given seed for its leaves
+func newSyntheticLog(t *testing.T, seed string) *syntheticLog {
+
+	log := &syntheticLog{}
+
+	for leafIndex := 0; leafIndex < syntheticLeafCount; leafIndex++ {
+
+		leaf := sha256.Sum256([]byte(fmt.Sprintf("%s leaf %d", seed, leafIndex)))
+
+		_, err := mmr.AddHashedLeaf(log, sha256.New(), leaf[:])
+		require.NoError(t, err)
+	}
+
+	return log
+}
+
+// Append a node to the synthetic log
+func (sl *syntheticLog) Append(value []byte) (uint64, error) {
+
+	sl.nodes = append(sl.nodes, value)
+
+	return uint64(len(sl.nodes)), nil
+}
+
+// Get the node at the given mmr index
+func (sl *syntheticLog) Get(mmrIndex uint64) ([]byte, error) {
+
+	if mmrIndex >= uint64(len(sl.nodes)) {
+		return nil, fmt.Errorf("mmr index %d is beyond the synthetic log", mmrIndex)
+	}
+
+	return sl.nodes[mmrIndex], nil
+}
+
+// logState gets the log state of the synthetic log after the given number of leaves
+func (sl *syntheticLog) logState(t *testing.T, leafCount uint64) *massifs.MMRState {
+
+	mmrSize := mmr.TreeIndex(leafCount)
+
+	root, err := mmr.GetRoot(mmrSize, sl, sha256.New())
+	require.NoError(t, err)
+
+	return &massifs.MMRState{MMRSize: mmrSize, Root: root}
+}
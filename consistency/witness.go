@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/datatrails/go-datatrails-demos/verification/witness"
+)
+
+/**
+ * Witness options of the consistency demo, the co-signing and the quorum of witnesses are in the witness package.
+ */
+
+// WitnessOptions configure the co-signing of log states verified as consistent,
+//
+//	and the quorum of witnesses that must have co-signed the newer log state.
+type WitnessOptions struct {
+
+	// Witness co-signs the newer log state, no co-signature is made if nil
+	Witness *witness.Witness
+
+	// Dir to write the co-signatures to
+	Dir string
+
+	// Policy is the quorum of witnesses that must have co-signed the newer log state, not checked if nil
+	Policy *witness.Policy
+
+	// CoSignatures are the co-signatures of third party witnesses
+	CoSignatures [][]byte
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-demos/verification/witness"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/**
+ * Asset verifies all the events of a datatrails asset, fetched from the datatrails events API.
+ *
+ * Each event is verified as included on the merklelog, its merklelog confirmation and signed tree heads
+ *  are verified, and optionally the seal of the massif it is in is verified as co-signed by a quorum of witnesses.
+ *
+ * The asset may be verified again every interval, as new events are added, exposing metrics of the verifications.
+ */
+
+// AssetOptions configure the verification of the events of an asset
+type AssetOptions struct {
+
+	// Diagnose the events NOT included on the merklelog
+	Diagnose bool
+
+	// Policy of the quorum of witnesses that must co-sign the seals, not verified if nil
+	Policy *witness.Policy
+
+	// CoSignatures of the witnesses of the policy
+	CoSignatures [][]byte
+}
+
+// AssetDemo verifies all the events of the asset with the given identity, printing the result of each verification,
+//
+//	returns true if any event is not included on the merklelog, is inconsistent with it, or is not witnessed.
+func AssetDemo(ctx context.Context, eventsAPI *events.EventsAPI, assetIdentity string, options AssetOptions) (inconsistent bool, err error) {
+
+	eventsJson, err := eventsAPI.AssetEvents(ctx, assetIdentity)
+	if err != nil {
+		return false, err
+	}
+
+	verified, err := BatchInclusionDemo(ctx, eventsJson)
+	if err != nil {
+		return false, err
+	}
+
+	confirmations, err := merklelog.VerifyEventConfirmations(ctx, eventsJson)
+	if err != nil {
+		return false, err
+	}
+
+	for _, confirmation := range confirmations {
+		logConfirmation(confirmation)
+		inconsistent = inconsistent || confirmation.Failed()
+	}
+
+	eventIDs := make([]string, 0, len(verified))
+	for eventID := range verified {
+		eventIDs = append(eventIDs, eventID)
+	}
+	sort.Strings(eventIDs)
+
+	for _, eventID := range eventIDs {
+
+		inconsistent = inconsistent || !verified[eventID]
+
+		if !verified[eventID] {
+			slog.Error("event not included", logging.KeyEventIdentity, eventID)
+			continue
+		}
+
+		slog.Info("event inclusion", logging.KeyEventIdentity, eventID, "included", verified[eventID])
+	}
+
+	if options.Diagnose {
+
+		diagnoses, err := DiagnosesDemo(ctx, eventsJson)
+		if err != nil {
+			return false, err
+		}
+
+		for _, diagnosis := range diagnoses {
+			if !verified[diagnosis.Identity] {
+				logDiagnosis(diagnosis)
+			}
+		}
+	}
+
+	if options.Policy != nil {
+
+		witnessQuorums, err := WitnessQuorumsDemo(ctx, eventsJson, options.Policy, options.CoSignatures)
+		if err != nil {
+			return false, err
+		}
+
+		for _, witnessQuorum := range witnessQuorums {
+			logWitnessQuorum(witnessQuorum)
+			inconsistent = inconsistent || !witnessQuorum.Quorum.Met()
+		}
+	}
+
+	return inconsistent, nil
+}
+
+// AssetMonitorDemo verifies all the events of the asset with the given identity every interval,
+//
+//	until the given context is done, serving the metrics on the given address, if any.
+func AssetMonitorDemo(ctx context.Context, eventsAPI *events.EventsAPI, assetIdentity string, interval time.Duration, metricsListen string, options AssetOptions) error {
+
+	if metricsListen != "" {
+
+		go func() {
+			err := metrics.Serve(ctx, metricsListen)
+			if err != nil {
+				slog.Error("failed to serve the metrics", logging.KeyError, err)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		assetMonitorRound(ctx, eventsAPI, assetIdentity, options)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// assetMonitorRound verifies all the events of the asset with the given identity once,
+//
+//	a failed round is reported, and counted in the metrics, but the asset is verified again next interval.
+func assetMonitorRound(ctx context.Context, eventsAPI *events.EventsAPI, assetIdentity string, options AssetOptions) {
+
+	// each round is a trace of its own, rather than a child of the span of the whole run
+	ctx, span := tracing.Tracer.Start(ctx, "asset.round", trace.WithNewRoot(), trace.WithAttributes(attribute.String(logging.KeyAssetIdentity, assetIdentity)))
+	defer span.End()
+
+	inconsistent, err := AssetDemo(ctx, eventsAPI, assetIdentity, options)
+	switch {
+	case err != nil:
+		slog.Error("failed to verify the asset", logging.KeyAssetIdentity, assetIdentity, logging.KeyError, err)
+	case inconsistent:
+		slog.Error("ALERT: events of the asset are not included on the merklelog, are inconsistent with it, or are not witnessed", logging.KeyAssetIdentity, assetIdentity)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfirmationDemo tests the confirmed root of the sample event
+//
+//	matches the root recomputed from the merklelog.
+func TestConfirmationDemo(t *testing.T) {
+
+	confirmation, err := merklelog.VerifyEventConfirmation(context.Background(), []byte(event))
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, merklelog.ConfirmVerified, confirmation.Status)
+
+	// the sample event has no signed tree heads
+	assert.Equal(t, merklelog.TreeHeadAbsent, confirmation.SignedTreeHead)
+	assert.Equal(t, merklelog.TreeHeadAbsent, confirmation.Unequivocal)
+
+}
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/zeebo/bencode"
+)
+
+/**
+ * Diagnostics explain why a datatrails event does not hash to the leaf at its
+ *  merklelog_entry.commit.index.
+ *
+ * An event is hashed onto the merklelog by canonicalising the V3 fields of the event with bencode,
+ *  which sorts the keys of every dictionary, then hashing:
+ *
+ *    sha256(leaf type || idtimestamp || canonical event)
+ *
+ * If the hash does not match the leaf, the event has been transformed since it was committed.
+ *  The diagnosis recomputes the hash with the likely transformations undone, such as changed
+ *  timestamp precision or added whitespace, and reports any transformation that matches the leaf.
+ */
+
+const (
+	// leafTypePlain is the domain separation byte of a leaf committing a datatrails event
+	leafTypePlain = 0
+
+	// idTimestampEpochBytes is the number of bytes of the epoch prefixing an idtimestamp in the events API
+	idTimestampEpochBytes = 1
+)
+
+var (
+	// v3Fields are the fields of a datatrails event hashed onto the merklelog
+	v3Fields = []string{
+		"identity",
+		"event_attributes",
+		"asset_attributes",
+		"operation",
+		"behaviour",
+		"timestamp_declared",
+		"timestamp_accepted",
+		"timestamp_committed",
+		"principal_declared",
+		"principal_accepted",
+		"tenant_identity",
+	}
+
+	// timestampFields are the v3 fields holding RFC3339 timestamps
+	timestampFields = []string{"timestamp_declared", "timestamp_accepted", "timestamp_committed"}
+
+	// attributeFields are the v3 fields holding attribute dictionaries
+	attributeFields = []string{"event_attributes", "asset_attributes"}
+
+	ErrInvalidIDTimestamp = errors.New("invalid merklelog_entry.commit.idtimestamp")
+)
+
+// canonicalTransformation undoes a transformation the event may have undergone since it was committed
+type canonicalTransformation struct {
+	description string
+	undo        func(fields map[string]any) map[string]any
+}
+
+// canonicalTransformations are the transformations tried when the event does not hash to its leaf
+var canonicalTransformations = []canonicalTransformation{
+	{description: "timestamps truncated to second precision", undo: timestampPrecision("2006-01-02T15:04:05Z07:00")},
+	{description: "timestamps at millisecond precision", undo: timestampPrecision("2006-01-02T15:04:05.000Z07:00")},
+	{description: "timestamps at microsecond precision", undo: timestampPrecision("2006-01-02T15:04:05.000000Z07:00")},
+	{description: "timestamps at nanosecond precision", undo: timestampPrecision("2006-01-02T15:04:05.000000000Z07:00")},
+	{description: "timestamps with trailing zeros trimmed", undo: timestampPrecision(time.RFC3339Nano)},
+	{description: "leading and trailing whitespace trimmed from string values", undo: trimWhitespace},
+	{description: "empty principal fields removed", undo: dropEmptyPrincipalFields},
+}
+
+// EventDiagnosis explains how an event hashes onto the merklelog, compared to the leaf at its mmr index.
+type EventDiagnosis struct {
+	Identity string
+
+	// MMRIndex is the merklelog_entry.commit.index of the event
+	MMRIndex uint64
+
+	// IDTimestamp is the merklelog_entry.commit.idtimestamp of the event, without its epoch
+	IDTimestamp uint64
+
+	// CanonicalEvent is the bencoded v3 fields of the event, as hashed onto the merklelog
+	CanonicalEvent []byte
+
+	// EventHash is the leaf hash computed for the event
+	EventHash []byte
+
+	// LeafHash is the leaf hash stored on the merklelog at the mmr index of the event
+	LeafHash []byte
+
+	// Hints explain which fields, or transformations of the event, may have broken the hash
+	Hints []string
+}
+
+// Matches is true if the event hashes to the leaf stored at its mmr index
+func (ed EventDiagnosis) Matches() bool {
+	return bytes.Equal(ed.EventHash, ed.LeafHash)
+}
+
+// DiagnosisDemo diagnoses how a datatrails event hashes onto the merklelog
+func DiagnosisDemo(ctx context.Context, eventJson []byte) (*EventDiagnosis, error) {
+
+	diagnoses, err := DiagnosesDemo(ctx, []byte(fmt.Sprintf(`{"events": [%s]}`, eventJson)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagnoses[0], nil
+}
+
+// DiagnosesDemo diagnoses how every event in the given list of events hashes onto the merklelog
+func DiagnosesDemo(ctx context.Context, eventsJson []byte) ([]EventDiagnosis, error) {
+
+	eventList := struct {
+		Events []json.RawMessage `json:"events"`
+	}{}
+
+	err := json.Unmarshal(eventsJson, &eventList)
+	if err != nil {
+		return nil, err
+	}
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	massifCache := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight))
+
+	diagnoses := make([]EventDiagnosis, 0, len(eventList.Events))
+	for _, eventJson := range eventList.Events {
+
+		event := committedEvent{}
+		err := json.Unmarshal(eventJson, &event)
+		if err != nil {
+			return nil, err
+		}
+
+		// the leaf stored at the mmr index of the event
+		mmrIndex := event.MerklelogEntry.Commit.Index
+
+		massifContext, err := massifCache.Massif(mmrIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		leafHash, err := massifContext.Get(mmrIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		diagnosis, err := DiagnoseEvent(eventJson, leafHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diagnose event %s: %w", event.Identity, err)
+		}
+
+		diagnoses = append(diagnoses, *diagnosis)
+	}
+
+	return diagnoses, nil
+}
+
+// DiagnoseEvent computes the canonical bytes and leaf hash of the given event,
+//
+//	and compares them to the given leaf hash stored at the mmr index of the event.
+func DiagnoseEvent(eventJson []byte, leafHash []byte) (*EventDiagnosis, error) {
+
+	event := struct {
+		Identity       string `json:"identity"`
+		MerklelogEntry struct {
+			Commit struct {
+				Index       uint64 `json:"index,string"`
+				Idtimestamp string `json:"idtimestamp"`
+			} `json:"commit"`
+		} `json:"merklelog_entry"`
+	}{}
+
+	err := json.Unmarshal(eventJson, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	idTimestamp, err := ParseIDTimestamp(event.MerklelogEntry.Commit.Idtimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := v3EventFields(eventJson)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEvent, err := bencode.EncodeBytes(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis := &EventDiagnosis{
+		Identity:       event.Identity,
+		MMRIndex:       event.MerklelogEntry.Commit.Index,
+		IDTimestamp:    idTimestamp,
+		CanonicalEvent: canonicalEvent,
+		EventHash:      LeafHash(idTimestamp, canonicalEvent),
+		LeafHash:       leafHash,
+	}
+
+	if diagnosis.Matches() {
+		return diagnosis, nil
+	}
+
+	// now try undoing each likely transformation of the event, to find the one that broke the hash
+	for _, transformation := range canonicalTransformations {
+
+		transformedEvent, err := bencode.EncodeBytes(transformation.undo(fields))
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(LeafHash(idTimestamp, transformedEvent), leafHash) {
+			diagnosis.Hints = append(diagnosis.Hints, fmt.Sprintf("the leaf matches the event with %s", transformation.description))
+		}
+	}
+
+	diagnosis.Hints = append(diagnosis.Hints, fieldHints(eventJson, fields)...)
+
+	if len(diagnosis.Hints) == 0 {
+		diagnosis.Hints = append(diagnosis.Hints, "no known transformation of the event matches the leaf, the event, or its idtimestamp, may have been altered")
+	}
+
+	return diagnosis, nil
+}
+
+// LeafHash computes the merklelog leaf hash of the given canonical event, committed at the given idtimestamp
+func LeafHash(idTimestamp uint64, canonicalEvent []byte) []byte {
+
+	hasher := sha256.New()
+
+	hasher.Write([]byte{leafTypePlain})
+	hasher.Write(binary.BigEndian.AppendUint64(nil, idTimestamp))
+	hasher.Write(canonicalEvent)
+
+	return hasher.Sum(nil)
+}
+
+// ParseIDTimestamp parses the hex idtimestamp of an event, as returned by the datatrails events API,
+//
+//	dropping the epoch prefix.
+func ParseIDTimestamp(idTimestamp string) (uint64, error) {
+
+	idTimestampBytes, err := hex.DecodeString(idTimestamp)
+	if err != nil || len(idTimestampBytes) != idTimestampEpochBytes+8 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidIDTimestamp, idTimestamp)
+	}
+
+	return binary.BigEndian.Uint64(idTimestampBytes[idTimestampEpochBytes:]), nil
+}
+
+// v3EventFields gets the v3 fields of the given event, keeping numbers as they appear in the json
+func v3EventFields(eventJson []byte) (map[string]any, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(eventJson))
+	decoder.UseNumber()
+
+	event := map[string]any{}
+	err := decoder.Decode(&event)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]any, len(v3Fields))
+	for _, field := range v3Fields {
+		if value, ok := event[field]; ok {
+			fields[field] = value
+		}
+	}
+
+	return fields, nil
+}
+
+// fieldHints describes the fields of the event likely to have been transformed by the events API
+func fieldHints(eventJson []byte, fields map[string]any) []string {
+
+	hints := []string{}
+
+	for _, field := range v3Fields {
+		if _, ok := fields[field]; !ok {
+			hints = append(hints, fmt.Sprintf("%s is missing from the event", field))
+		}
+	}
+
+	for _, field := range timestampFields {
+
+		timestamp, ok := fields[field].(string)
+		if !ok {
+			continue
+		}
+
+		_, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			hints = append(hints, fmt.Sprintf("%s %q is not an RFC3339 timestamp", field, timestamp))
+			continue
+		}
+
+		if !strings.HasSuffix(timestamp, "Z") {
+			hints = append(hints, fmt.Sprintf("%s %q is not in UTC", field, timestamp))
+		}
+	}
+
+	for _, field := range v3Fields {
+		if hasSurroundingWhitespace(fields[field]) {
+			hints = append(hints, fmt.Sprintf("%s has string values with leading or trailing whitespace", field))
+		}
+	}
+
+	// bencode sorts the keys of dictionaries, so only the order of list items can break the hash
+	for _, field := range attributeFields {
+		if hasListValues(fields[field]) {
+			hints = append(hints, fmt.Sprintf("%s has list values, reordering list items breaks the hash, unlike reordering keys", field))
+		}
+	}
+
+	return hints
+}
+
+// timestampPrecision reformats every timestamp field using the given layout
+func timestampPrecision(layout string) func(fields map[string]any) map[string]any {
+
+	return func(fields map[string]any) map[string]any {
+
+		transformed := copyFields(fields)
+		for _, field := range timestampFields {
+
+			timestamp, ok := fields[field].(string)
+			if !ok {
+				continue
+			}
+
+			parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+			if err != nil {
+				continue
+			}
+
+			transformed[field] = parsed.Format(layout)
+		}
+
+		return transformed
+	}
+}
+
+// trimWhitespace trims leading and trailing whitespace from every string value
+func trimWhitespace(fields map[string]any) map[string]any {
+	return mapStrings(fields, strings.TrimSpace).(map[string]any)
+}
+
+// dropEmptyPrincipalFields removes the empty fields of the principals
+func dropEmptyPrincipalFields(fields map[string]any) map[string]any {
+
+	transformed := copyFields(fields)
+	for _, field := range []string{"principal_declared", "principal_accepted"} {
+
+		principal, ok := fields[field].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		nonEmpty := map[string]any{}
+		for key, value := range principal {
+			if value != "" {
+				nonEmpty[key] = value
+			}
+		}
+
+		transformed[field] = nonEmpty
+	}
+
+	return transformed
+}
+
+// copyFields makes a shallow copy of the event fields
+func copyFields(fields map[string]any) map[string]any {
+
+	copied := make(map[string]any, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+// mapStrings applies the given function to every string value, recursively
+func mapStrings(value any, f func(string) string) any {
+
+	switch v := value.(type) {
+	case string:
+		return f(v)
+	case map[string]any:
+		mapped := make(map[string]any, len(v))
+		for key, item := range v {
+			mapped[key] = mapStrings(item, f)
+		}
+		return mapped
+	case []any:
+		mapped := make([]any, 0, len(v))
+		for _, item := range v {
+			mapped = append(mapped, mapStrings(item, f))
+		}
+		return mapped
+	default:
+		return v
+	}
+}
+
+// hasSurroundingWhitespace is true if any string value has leading or trailing whitespace, recursively
+func hasSurroundingWhitespace(value any) bool {
+
+	found := false
+	mapStrings(value, func(s string) string {
+		found = found || s != strings.TrimSpace(s)
+		return s
+	})
+
+	return found
+}
+
+// hasListValues is true if any value of the given attributes is a list
+func hasListValues(attributes any) bool {
+
+	attributeMap, ok := attributes.(map[string]any)
+	if !ok {
+		return false
+	}
+
+	for _, value := range attributeMap {
+		if _, ok := value.([]any); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleLeafHash computes the leaf hash of the sample event, as it was committed to the merklelog,
+//
+//	independently of the diagnosis, with the leaf hash of logverification.
+func sampleLeafHash(t *testing.T) []byte {
+
+	verifiableEvent, err := logverification.NewVerifiableEvent([]byte(event))
+	require.NoError(t, err)
+
+	return verifiableEvent.LeafHash
+}
+
+// TestDiagnoseEvent_LeafHash tests the leaf hash computed by the diagnosis of the sample event,
+//
+//	matches the leaf hash computed by logverification.
+func TestDiagnoseEvent_LeafHash(t *testing.T) {
+
+	diagnosis, err := DiagnoseEvent([]byte(event), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, sampleLeafHash(t), diagnosis.EventHash)
+}
+
+// TestParseIDTimestamp tests the epoch is dropped from the idtimestamp of an event
+func TestParseIDTimestamp(t *testing.T) {
+
+	idTimestamp, err := ParseIDTimestamp("018f54c34a730ce300")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(0x8f54c34a730ce300), idTimestamp)
+
+	_, err = ParseIDTimestamp("8f54c34a730ce300")
+	assert.ErrorIs(t, err, ErrInvalidIDTimestamp)
+}
+
+// TestDiagnoseEvent_Transformed tests the hints for events transformed since they were committed
+func TestDiagnoseEvent_Transformed(t *testing.T) {
+
+	leafHash := sampleLeafHash(t)
+
+	tests := []struct {
+		name     string
+		original string
+		replaced string
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			expected: nil,
+		},
+		{
+			name:     "timestamp precision",
+			original: `"timestamp_committed": "2024-05-07T20:32:27.235Z"`,
+			replaced: `"timestamp_committed": "2024-05-07T20:32:27.235000Z"`,
+			expected: []string{"the leaf matches the event with timestamps with trailing zeros trimmed"},
+		},
+		{
+			name:     "whitespace",
+			original: `"approvers": "Product Team"`,
+			replaced: `"approvers": "Product Team "`,
+			expected: []string{
+				"the leaf matches the event with leading and trailing whitespace trimmed from string values",
+				"event_attributes has string values with leading or trailing whitespace",
+			},
+		},
+		{
+			name:     "list ordering",
+			original: `"approvers": "Product Team"`,
+			replaced: `"approvers": ["Product Team", "Legal Team"]`,
+			expected: []string{
+				"event_attributes has list values, reordering list items breaks the hash, unlike reordering keys",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			eventJson := strings.Replace(event, test.original, test.replaced, 1)
+
+			diagnosis, err := DiagnoseEvent([]byte(eventJson), leafHash)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected == nil, diagnosis.Matches())
+			assert.Equal(t, test.expected, diagnosis.Hints)
+		})
+	}
+}
+
+// TestDiagnosisDemo tests the sample event hashes to the leaf stored at its mmr index
+func TestDiagnosisDemo(t *testing.T) {
+
+	diagnosis, err := DiagnosisDemo(context.Background(), []byte(event))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(499), diagnosis.MMRIndex)
+	assert.Equal(t, true, diagnosis.Matches())
+	assert.Equal(t, 0, len(diagnosis.Hints))
+}
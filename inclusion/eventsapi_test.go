@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInclusionDemo_EventsAPI tests an event got from the events API
+//
+//	is included on the merklelog.
+func TestInclusionDemo_EventsAPI(t *testing.T) {
+
+	eventsURL := eventstest.NewMockEventsAPI(t, events.PageSize, event)
+
+	eventsJson, err := events.NewEventsAPI(eventsURL).AssetEvents(context.Background(), "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6")
+	require.NoError(t, err)
+
+	verified, err := BatchInclusionDemo(context.Background(), eventsJson)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]bool{"publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601": true}, verified)
+
+}
@@ -1,9 +1,23 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-demos/verification/witness"
 	"github.com/datatrails/go-datatrails-logverification/logverification"
 )
 
@@ -76,18 +90,21 @@ const (
 	}
 	`
 
-	publicTenantID = "tenant/6ea5cd00-c711-3649-6914-7b125928bbb4"
-
-	// merklelog reader configuration
-	container = "merklelogs"
-	url       = "https://app.datatrails.ai/verifiabledata"
+	// types of verification of the metrics
+	verificationInclusion     = "inclusion"
+	verificationWitnessQuorum = "witness_quorum"
 )
 
 // InclusionDemo of a public datatrails event
-func InclusionDemo(eventJson []byte) (verified bool, err error) {
+func InclusionDemo(ctx context.Context, eventJson []byte) (verified bool, err error) {
+
+	err = events.CheckEventTenants(eventJson)
+	if err != nil {
+		return false, err
+	}
 
 	// then create the merklelog reader
-	reader, err := azblob.NewReaderNoAuth(url, azblob.WithContainer(container))
+	reader, err := merklelog.NewReader(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -97,19 +114,282 @@ func InclusionDemo(eventJson []byte) (verified bool, err error) {
 		return false, err
 	}
 
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		return false, err
+	}
+
 	// now verify the public event is in the merklelog
-	return logverification.VerifyEvent(reader, *verifiableEvent, logverification.WithMassifTenantId(publicTenantID))
+	return merklelog.VerifyEventInclusion(ctx, reader, *verifiableEvent, config.TenantID, massifHeight)
+
+}
+
+// BatchInclusionDemo of a list of datatrails events, returns the inclusion
+//
+//	verification result of each event, keyed by the event identity.
+func BatchInclusionDemo(ctx context.Context, eventsJson []byte) (verified map[string]bool, err error) {
+
+	err = events.CheckEventTenants(eventsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiableEvents, err := logverification.NewVerifiableEvents(eventsJson)
+	if err != nil {
+		return nil, err
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// now verify each event is in the merklelog
+	verified = make(map[string]bool, len(verifiableEvents))
+	for _, verifiableEvent := range verifiableEvents {
+
+		eventVerified, err := merklelog.VerifyEventInclusion(ctx, reader, verifiableEvent, config.TenantID, massifHeight)
+		metrics.Verification(verificationInclusion, eventVerified, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify event %s: %w", verifiableEvent.EventID, err)
+		}
+
+		verified[verifiableEvent.EventID] = eventVerified
+	}
+
+	return verified, nil
 
 }
 
 // Demo of the inclusion of a public datatrails event
+//
+// By default the sample event is verified, otherwise the event, or all the events
+// of the asset, with the given identity are fetched from the datatrails events API.
 func main() {
 
-	verified, err := InclusionDemo([]byte(event))
+	eventIdentity := flag.String("event", "", "identity of the event to verify, e.g. publicassets/<uuid>/events/<uuid>")
+	assetIdentity := flag.String("asset", "", "identity of the asset to verify all events of, e.g. publicassets/<uuid>")
+	eventsURL := flag.String("events-url", events.DefaultURL, "base url of the datatrails events API")
+	diagnose := flag.Bool("diagnose", false, "print the canonical bytes and hashes of events NOT included on the merkle log, with hints of what broke the hash")
+	witnessPolicy := flag.String("witness-policy", "", "witness policy file of the quorum of witnesses that must co-sign the seal of the massif of each event")
+	coSignatures := flag.String("cosignatures", "", "comma separated co-signature files, or directories of them, of the witnesses of the witness policy")
+	interval := flag.Duration("interval", 0, "verify all events of the -asset again every interval, until interrupted, e.g. 5m")
+	metricsListen := flag.String("metrics-listen", "", "address to serve prometheus metrics on /metrics when verifying an -asset every -interval, e.g. :9090")
+	config.Flags(flag.CommandLine)
+	flag.Parse()
+
+	_, err := config.Load(flag.CommandLine, "inclusion")
+	if err != nil {
+		fmt.Printf("\nerror: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = logging.Setup(config.LogLevel, config.LogFormat)
 	if err != nil {
 		fmt.Printf("\nerror: %v\n", err)
+		os.Exit(1)
 	}
 
-	fmt.Printf("\nEvent included on merkle log: %v\n", verified)
+	// the massif readers log to the datatrails logger, which is set up once here, discarding its logs
+	logger.New("NOOP")
+
+	ctx, err := tracing.Setup(context.Background(), "inclusion", config.TraceExporter)
+	if err != nil {
+		slog.Error("failed to set up tracing", logging.KeyError, err)
+		os.Exit(1)
+	}
+	defer tracing.Flush()
+
+	var policy *witness.Policy
+	var policyCoSignatures [][]byte
+	if *witnessPolicy != "" {
+
+		policy, policyCoSignatures, err = witness.ReadQuorumFiles(*witnessPolicy, config.SplitList(*coSignatures))
+		if err != nil {
+			slog.Error("failed to read the witness policy", logging.KeyError, err)
+			tracing.Exit(1)
+		}
+	}
+
+	assetOptions := AssetOptions{
+		Diagnose:     *diagnose,
+		Policy:       policy,
+		CoSignatures: policyCoSignatures,
+	}
+
+	eventsAPI := events.NewEventsAPI(*eventsURL, events.WithBearerToken(os.Getenv(events.BearerTokenEnv)))
+
+	if *assetIdentity != "" && *interval > 0 {
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := AssetMonitorDemo(ctx, eventsAPI, *assetIdentity, *interval, *metricsListen, assetOptions)
+		if err != nil {
+			slog.Error("failed to monitor the asset", logging.KeyAssetIdentity, *assetIdentity, logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		return
+	}
+
+	if *assetIdentity != "" {
+
+		inconsistent, err := AssetDemo(ctx, eventsAPI, *assetIdentity, assetOptions)
+		if err != nil {
+			slog.Error("failed to verify the asset", logging.KeyAssetIdentity, *assetIdentity, logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		if inconsistent {
+			tracing.Exit(1)
+		}
+
+		return
+	}
+
+	eventJson := []byte(event)
+	if *eventIdentity != "" {
+
+		var err error
+		eventJson, err = eventsAPI.Event(ctx, *eventIdentity)
+		if err != nil {
+			slog.Error("failed to get the event", logging.KeyEventIdentity, *eventIdentity, logging.KeyError, err)
+			tracing.Exit(1)
+		}
+	}
+
+	identity := eventIdentityOf(eventJson)
+
+	// the confirmation, diagnosis and witness quorum of the event are all read from the merklelog
+	//  of the configured tenant, inclusion fails first if the event is not on it
+	verified, err := InclusionDemo(ctx, eventJson)
+	if err != nil {
+		slog.Error("failed to verify the event inclusion", logging.KeyEventIdentity, identity, logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	if !verified {
+
+		slog.Error("event not included", logging.KeyEventIdentity, identity)
+
+		if *diagnose {
+
+			diagnosis, err := DiagnosisDemo(ctx, eventJson)
+			if err != nil {
+				slog.Error("failed to diagnose the event", logging.KeyEventIdentity, identity, logging.KeyError, err)
+				tracing.Exit(1)
+			}
+
+			logDiagnosis(*diagnosis)
+		}
+
+		tracing.Exit(1)
+	}
+
+	slog.Info("event inclusion", logging.KeyEventIdentity, identity, "included", verified)
+
+	// the event also confirms the root of the merklelog at an mmr size that includes the event
+	//  and may carry signed tree heads committing to the merklelog.
+	confirmation, err := merklelog.VerifyEventConfirmation(ctx, eventJson)
+	if err != nil {
+		slog.Error("failed to verify the event confirmation", logging.KeyEventIdentity, identity, logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	logConfirmation(confirmation)
+
+	if confirmation.Failed() {
+		tracing.Exit(1)
+	}
+
+	// the seal of the massif the event is in may also need to be co-signed by a quorum of witnesses
+	if policy != nil {
+
+		witnessQuorum, err := WitnessQuorumDemo(ctx, eventJson, policy, policyCoSignatures)
+		if err != nil {
+			slog.Error("failed to verify the witness quorum", logging.KeyEventIdentity, identity, logging.KeyError, err)
+			tracing.Exit(1)
+		}
+
+		logWitnessQuorum(witnessQuorum)
+
+		if !witnessQuorum.Quorum.Met() {
+			tracing.Exit(1)
+		}
+	}
+
+}
+
+// committedEvent is the part of a datatrails event needed to find its leaf on the merklelog
+type committedEvent struct {
+	Identity       string `json:"identity"`
+	MerklelogEntry struct {
+		Commit struct {
+			Index uint64 `json:"index,string"`
+		} `json:"commit"`
+	} `json:"merklelog_entry"`
+}
+
+// eventIdentityOf gets the identity of the given datatrails event, for logging, empty if it has none
+func eventIdentityOf(eventJson []byte) string {
+
+	identified := struct {
+		Identity string `json:"identity"`
+	}{}
+
+	// an event that does not parse fails verification, and is reported there
+	_ = json.Unmarshal(eventJson, &identified)
+
+	return identified.Identity
+}
+
+// logConfirmation logs the result of verifying the merklelog confirmation, and signed tree heads, of an event
+func logConfirmation(confirmation merklelog.EventConfirmation) {
+
+	level := slog.LevelInfo
+	if confirmation.Failed() {
+		level = slog.LevelError
+	}
+
+	slog.Log(context.Background(), level, "event merklelog confirmation",
+		logging.KeyEventIdentity, confirmation.Identity,
+		"status", confirmation.Status,
+		"signed_tree_head", confirmation.SignedTreeHead,
+		"unequivocal_signed_tree_head", confirmation.Unequivocal)
+}
+
+// logDiagnosis logs the canonical bytes and hashes of an event, and the hints of what broke its hash
+func logDiagnosis(diagnosis EventDiagnosis) {
+
+	slog.Warn("event diagnosis",
+		logging.KeyEventIdentity, diagnosis.Identity,
+		logging.KeyMMRIndex, diagnosis.MMRIndex,
+		"idtimestamp", fmt.Sprintf("%016x", diagnosis.IDTimestamp),
+		"canonical_event", string(diagnosis.CanonicalEvent),
+		"event_hash", fmt.Sprintf("%x", diagnosis.EventHash),
+		"leaf_hash", fmt.Sprintf("%x", diagnosis.LeafHash),
+		"hints", diagnosis.Hints)
+}
+
+// logWitnessQuorum logs the witnesses that co-signed the seal of the massif of an event
+func logWitnessQuorum(witnessQuorum EventWitnessQuorum) {
+
+	level := slog.LevelInfo
+	if !witnessQuorum.Quorum.Met() {
+		level = slog.LevelError
+	}
 
+	slog.Log(context.Background(), level, "event seal witness quorum",
+		logging.KeyEventIdentity, witnessQuorum.Identity,
+		logging.KeyMassifIndex, witnessQuorum.MassifIndex,
+		"met", witnessQuorum.Quorum.Met(),
+		"threshold", witnessQuorum.Quorum.Threshold,
+		"witnessed", witnessQuorum.Quorum.Witnessed)
 }
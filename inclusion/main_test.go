@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
+	"os"
 	"testing"
 
+	"github.com/datatrails/go-datatrails-common/logger"
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain sets up the datatrails logger the massif readers log to, as main does
+func TestMain(m *testing.M) {
+
+	logger.New("NOOP")
+
+	os.Exit(m.Run())
+}
+
 // TestInclusionDemo tests the sample public event
 //
 //	is included on the merklelog.
 func TestInclusionDemo(t *testing.T) {
 
-	verified, err := InclusionDemo([]byte(event))
+	verified, err := InclusionDemo(context.Background(), []byte(event))
 
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, verified)
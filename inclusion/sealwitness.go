@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/cose"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-demos/verification/witness"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * Seal witness verifies the seal of the massif an event is in is co-signed by a quorum
+ *  of third party witnesses, as well as by datatrails.
+ *
+ * So an event is only trusted to be included on a merklelog that independent witnesses
+ *  have also observed.
+ */
+
+// EventWitnessQuorum is the witness quorum of the seal of the massif an event is in
+type EventWitnessQuorum struct {
+	Identity string
+
+	// MassifIndex is the index of the massif the event is in, whose seal is co-signed
+	MassifIndex uint64
+
+	// Quorum is the result of verifying the co-signatures of the seal against the witness policy
+	Quorum witness.Quorum
+}
+
+// WitnessQuorumDemo verifies the seal of the massif a datatrails event is in is co-signed by
+//
+//	the quorum of witnesses of the given policy.
+func WitnessQuorumDemo(ctx context.Context, eventJson []byte, policy *witness.Policy, coSignatures [][]byte) (EventWitnessQuorum, error) {
+
+	witnessQuorums, err := WitnessQuorumsDemo(ctx, []byte(fmt.Sprintf(`{"events": [%s]}`, eventJson)), policy, coSignatures)
+	if err != nil {
+		return EventWitnessQuorum{}, err
+	}
+
+	return witnessQuorums[0], nil
+}
+
+// WitnessQuorumsDemo verifies the witness quorum of the seal of every event in the given list of events
+func WitnessQuorumsDemo(ctx context.Context, eventsJson []byte, policy *witness.Policy, coSignatures [][]byte) ([]EventWitnessQuorum, error) {
+
+	eventList := struct {
+		Events []committedEvent `json:"events"`
+	}{}
+
+	err := json.Unmarshal(eventsJson, &eventList)
+	if err != nil {
+		return nil, err
+	}
+
+	// then create the merklelog reader
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := massifs.NewRootSignerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	verificationKey, err := merklelog.VerificationKeyFromFile(config.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	massifHeight, err := merklelog.NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight)).MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	// many events are in the same massif, so each seal is only read, and its quorum verified, once
+	massifQuorums := map[uint64]witness.Quorum{}
+
+	witnessQuorums := make([]EventWitnessQuorum, 0, len(eventList.Events))
+	for _, event := range eventList.Events {
+
+		massifIndex := massifs.MassifIndexFromMMRIndex(massifHeight, event.MerklelogEntry.Commit.Index)
+
+		quorum, ok := massifQuorums[massifIndex]
+		if !ok {
+
+			var signedState *cose.CoseSign1Message
+			start := time.Now()
+			signedState, err = merklelog.ReadSignedLogState(ctx, reader, sha256.New(), codec, config.TenantID, massifIndex)
+			metrics.BlobFetch(metrics.BlobSeal, start)
+			logging.BlobFetch(config.TenantID, massifIndex, massifs.TenantMassifSignedRootPath(config.TenantID, uint32(massifIndex)), start, err)
+			if err != nil {
+				return nil, err
+			}
+
+			// the seal must be signed by datatrails, before the witnesses are counted
+			err = merklelog.VerifySignature(ctx, signedState, verificationKey)
+			if err != nil {
+				return nil, err
+			}
+
+			quorum = witness.VerifyQuorum(policy, signedState, coSignatures)
+			metrics.Verification(verificationWitnessQuorum, quorum.Met(), nil)
+
+			massifQuorums[massifIndex] = quorum
+		}
+
+		witnessQuorums = append(witnessQuorums, EventWitnessQuorum{
+			Identity:    event.Identity,
+			MassifIndex: massifIndex,
+			Quorum:      quorum,
+		})
+	}
+
+	return witnessQuorums, nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/datatrails/go-datatrails-demos/service/verificationpb"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/**
+ * The gRPC API of the service, for high volume internal callers, defined by verificationpb/verification.proto.
+ *
+ * The rpcs are:
+ *  1. VerifyEvent an event, as returned by the datatrails events API, to get the verdict of the event.
+ *  2. VerifyEvents an event list to get the verdict of each event, streamed as each verification completes.
+ *  3. VerifyConsistency to get the verdict of a new log state, by default the newest seal, verified
+ *     consistent with the trusted log state.
+ *
+ * The rpcs share the reader, and the limits, of the http routes, so a request verified over either
+ *  takes a slot of the most requests verified at once.
+ */
+
+// grpcServer serves the verification rpcs of a Service
+type grpcServer struct {
+	verificationpb.UnimplementedVerificationServer
+
+	service *Service
+}
+
+// limitedStream is a server stream whose context is done once the request timeout passes
+type limitedStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+// Context gets the context of the stream, limited by the request timeout
+func (ls *limitedStream) Context() context.Context {
+	return ls.ctx
+}
+
+// NewGRPCServer creates the grpc server of the given service, with the limits of the service
+func NewGRPCServer(service *Service) *grpc.Server {
+
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(int(service.options.MaxRequestBytes)),
+		grpc.ChainUnaryInterceptor(service.unaryLimits),
+		grpc.ChainStreamInterceptor(service.streamLimits),
+	)
+
+	verificationpb.RegisterVerificationServer(server, &grpcServer{service: service})
+
+	return server
+}
+
+// ServeGRPC serves the given grpc server on the given address until the given context is done,
+//
+//	then stops it gracefully, letting the requests being verified finish.
+func ServeGRPC(ctx context.Context, address string, server *grpc.Server) error {
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	// the requests being verified did not finish in time, so abandon them
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		server.Stop()
+	}
+
+	return nil
+}
+
+// VerifyEvent verifies the event of the request
+func (gs *grpcServer) VerifyEvent(ctx context.Context, request *verificationpb.VerifyEventRequest) (*verificationpb.EventVerdict, error) {
+
+	entry, err := events.NewEventEntry(request.GetEventJson())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v: event: %v", ErrInvalidRequest, err)
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "grpc.verify_event", attribute.String(logging.KeyEventIdentity, entry.Identity))
+	defer span.End()
+
+	treeHeadVerifier, err := gs.service.newTreeHeadVerifier(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return newEventVerdictProto(gs.service.verifyEvent(ctx, treeHeadVerifier, entry), 0), nil
+}
+
+// VerifyEvents verifies each event of the event list of the request, sending the verdict of each event
+//
+//	as soon as it is verified.
+func (gs *grpcServer) VerifyEvents(request *verificationpb.VerifyEventsRequest, stream verificationpb.Verification_VerifyEventsServer) error {
+
+	entries, err := events.NewEventEntries(request.GetEventsJson())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v: event list: %v", ErrInvalidRequest, err)
+	}
+
+	if len(entries) > gs.service.options.MaxEvents {
+		return status.Errorf(codes.ResourceExhausted, "%v: %d, at most %d", ErrTooManyEvents, len(entries), gs.service.options.MaxEvents)
+	}
+
+	ctx, span := tracing.StartSpan(stream.Context(), "grpc.verify_events", attribute.Int("events", len(entries)))
+	defer span.End()
+
+	treeHeadVerifier, err := gs.service.newTreeHeadVerifier(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	for index, entry := range entries {
+
+		// the request has been abandoned, so stop verifying the rest of the events
+		if ctx.Err() != nil {
+			return contextStatus(ctx)
+		}
+
+		err = stream.Send(newEventVerdictProto(gs.service.verifyEvent(ctx, treeHeadVerifier, entry), index))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyConsistency verifies the new log state selected by the request is consistent with the trusted log state
+func (gs *grpcServer) VerifyConsistency(ctx context.Context, request *verificationpb.VerifyConsistencyRequest) (*verificationpb.ConsistencyVerdict, error) {
+
+	newState := merklelog.NewStateSelector{}
+	switch selected := request.GetNewState().(type) {
+	case *verificationpb.VerifyConsistencyRequest_MmrIndex:
+		newState.MMRIndex = &selected.MmrIndex
+	case *verificationpb.VerifyConsistencyRequest_MassifIndex:
+		newState.MassifIndex = &selected.MassifIndex
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "grpc.verify_consistency")
+	defer span.End()
+
+	verdict, err := gs.service.verifyConsistency(ctx, newState)
+	if err != nil {
+		slog.Error("failed to verify the consistency of the log", logging.KeyTenant, config.TenantID, logging.KeyError, err)
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return &verificationpb.ConsistencyVerdict{
+		Tenant:     verdict.Tenant,
+		Trusted:    newLogStateProto(verdict.Trusted),
+		New:        newLogStateProto(verdict.New),
+		Consistent: verdict.Consistent,
+	}, nil
+}
+
+// unaryLimits abandons unary requests taking longer than the request timeout, and rejects requests
+//
+//	beyond the most verified at once.
+//
+// Like the http routes, a request abandoned keeps its slot until its verification returns.
+func (s *Service) unaryLimits(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+
+	if !s.takeSlot() {
+		return nil, status.Error(codes.ResourceExhausted, ErrServiceBusy.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.options.RequestTimeout)
+	defer cancel()
+
+	type result struct {
+		response any
+		err      error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-s.slots }()
+
+		response, err := handler(ctx, request)
+		done <- result{response: response, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.response, result.err
+	case <-ctx.Done():
+		return nil, contextStatus(ctx)
+	}
+}
+
+// streamLimits limits streaming requests to the request timeout, and rejects requests
+//
+//	beyond the most verified at once.
+func (s *Service) streamLimits(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	if !s.takeSlot() {
+		return status.Error(codes.ResourceExhausted, ErrServiceBusy.Error())
+	}
+	defer func() { <-s.slots }()
+
+	ctx, cancel := context.WithTimeout(stream.Context(), s.options.RequestTimeout)
+	defer cancel()
+
+	return handler(server, &limitedStream{ServerStream: stream, ctx: ctx})
+}
+
+// takeSlot takes a slot of the requests being verified, false if every slot is taken
+func (s *Service) takeSlot() bool {
+
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// contextStatus gets the grpc status of a request whose context is done
+func contextStatus(ctx context.Context) error {
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, ErrRequestTimeout.Error())
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// newEventVerdictProto gets the proto of the given event verdict, of the event at the given index of its event list
+func newEventVerdictProto(verdict EventVerdict, index int) *verificationpb.EventVerdict {
+
+	return &verificationpb.EventVerdict{
+		Identity:       verdict.Identity,
+		MmrIndex:       verdict.MMRIndex,
+		Included:       verdict.Included,
+		SignedTreeHead: newTreeHeadStatusProto(verdict.SignedTreeHead),
+		Unequivocal:    newTreeHeadStatusProto(verdict.Unequivocal),
+		Verified:       verdict.Verified,
+		Error:          verdict.Error,
+		Index:          uint32(index),
+	}
+}
+
+// newTreeHeadStatusProto gets the proto of the given tree head status, unspecified if the tree head was not verified
+func newTreeHeadStatusProto(treeHeadStatus merklelog.TreeHeadStatus) verificationpb.TreeHeadStatus {
+
+	switch treeHeadStatus {
+	case merklelog.TreeHeadAbsent:
+		return verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_ABSENT
+	case merklelog.TreeHeadVerified:
+		return verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_VERIFIED
+	case merklelog.TreeHeadInvalid:
+		return verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_INVALID
+	case merklelog.TreeHeadInconsistent:
+		return verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_INCONSISTENT
+	default:
+		return verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_UNSPECIFIED
+	}
+}
+
+// newLogStateProto gets the proto of the given log state
+func newLogStateProto(logState LogState) *verificationpb.LogState {
+	return &verificationpb.LogState{MmrSize: logState.MMRSize, Root: logState.Root, Timestamp: logState.Timestamp}
+}
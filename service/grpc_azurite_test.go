@@ -0,0 +1,107 @@
+//go:build azurite
+
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/service/verificationpb"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newAzuriteGRPCClient serves a new service reading the merklelog from azurite over grpc, returning a client of it
+func newAzuriteGRPCClient(t *testing.T) verificationpb.VerificationClient {
+
+	reader, err := merklelog.NewReader(context.Background())
+	require.NoError(t, err)
+
+	options := testServiceOptions()
+	options.MaxRequestBytes = 1 << 20
+	options.MaxConcurrent = 4
+
+	service, err := NewService(reader, options)
+	require.NoError(t, err)
+
+	return newGRPCClient(t, service)
+}
+
+// TestGRPCServer_VerifyEvent tests the verdict of an event included on the merklelog
+func TestGRPCServer_VerifyEvent(t *testing.T) {
+
+	client := newAzuriteGRPCClient(t)
+
+	verdict, err := client.VerifyEvent(context.Background(), &verificationpb.VerifyEventRequest{EventJson: []byte(sampleEvent)})
+	require.NoError(t, err)
+
+	assert.Equal(t, "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601", verdict.GetIdentity())
+	assert.Equal(t, uint64(499), verdict.GetMmrIndex())
+	assert.True(t, verdict.GetIncluded())
+	assert.Equal(t, verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_ABSENT, verdict.GetSignedTreeHead())
+	assert.Equal(t, verificationpb.TreeHeadStatus_TREE_HEAD_STATUS_ABSENT, verdict.GetUnequivocal())
+	assert.True(t, verdict.GetVerified())
+}
+
+// TestGRPCServer_VerifyEvents tests the verdict of each event of an event list is streamed,
+//
+//	with the index of the event in the list.
+func TestGRPCServer_VerifyEvents(t *testing.T) {
+
+	client := newAzuriteGRPCClient(t)
+
+	tamperedEvent := strings.Replace(sampleEvent, "Approving Model", "Rejecting Model", 1)
+	events := `{"events": [` + sampleEvent + `, ` + tamperedEvent + `]}`
+
+	stream, err := client.VerifyEvents(context.Background(), &verificationpb.VerifyEventsRequest{EventsJson: []byte(events)})
+	require.NoError(t, err)
+
+	verdicts := []*verificationpb.EventVerdict{}
+	for {
+
+		verdict, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		verdicts = append(verdicts, verdict)
+	}
+
+	require.Len(t, verdicts, 2)
+
+	assert.Equal(t, uint32(0), verdicts[0].GetIndex())
+	assert.True(t, verdicts[0].GetVerified())
+
+	assert.Equal(t, uint32(1), verdicts[1].GetIndex())
+	assert.False(t, verdicts[1].GetIncluded())
+	assert.False(t, verdicts[1].GetVerified())
+}
+
+// TestGRPCServer_VerifyConsistency tests the newest seal is verified consistent with the trusted log state
+func TestGRPCServer_VerifyConsistency(t *testing.T) {
+
+	client := newAzuriteGRPCClient(t)
+
+	verdict, err := client.VerifyConsistency(context.Background(), &verificationpb.VerifyConsistencyRequest{})
+	require.NoError(t, err)
+
+	assert.True(t, verdict.GetConsistent())
+	assert.Equal(t, config.DefaultTenantID, verdict.GetTenant())
+	assert.LessOrEqual(t, verdict.GetTrusted().GetMmrSize(), verdict.GetNew().GetMmrSize())
+
+	t.Run("massif not in blob storage", func(t *testing.T) {
+
+		_, err := client.VerifyConsistency(context.Background(), &verificationpb.VerifyConsistencyRequest{
+			NewState: &verificationpb.VerifyConsistencyRequest_MassifIndex{MassifIndex: 1},
+		})
+
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
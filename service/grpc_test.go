@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-demos/service/verificationpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the given service over an in memory connection, returning a client of it
+func newGRPCClient(t *testing.T, service *Service) verificationpb.VerificationClient {
+
+	listener := bufconn.Listen(1 << 20)
+
+	server := NewGRPCServer(service)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return verificationpb.NewVerificationClient(conn)
+}
+
+// TestGRPCServer_InvalidRequests tests requests that are invalid, or beyond the limits of the service,
+//
+//	are rejected before the merklelog is read.
+func TestGRPCServer_InvalidRequests(t *testing.T) {
+
+	service, err := NewService(nil, testServiceOptions())
+	require.NoError(t, err)
+
+	client := newGRPCClient(t, service)
+
+	t.Run("event not json", func(t *testing.T) {
+
+		_, err := client.VerifyEvent(context.Background(), &verificationpb.VerifyEventRequest{EventJson: []byte("not an event")})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), ErrInvalidRequest.Error())
+	})
+
+	t.Run("event too large", func(t *testing.T) {
+
+		event := `{"identity": "` + strings.Repeat("a", 1024) + `"}`
+		_, err := client.VerifyEvent(context.Background(), &verificationpb.VerifyEventRequest{EventJson: []byte(event)})
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("event list not json", func(t *testing.T) {
+
+		stream, err := client.VerifyEvents(context.Background(), &verificationpb.VerifyEventsRequest{EventsJson: []byte(`{"events": {}}`)})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("too many events", func(t *testing.T) {
+
+		events := `{"events": [{"identity": "a"}, {"identity": "b"}, {"identity": "c"}]}`
+		stream, err := client.VerifyEvents(context.Background(), &verificationpb.VerifyEventsRequest{EventsJson: []byte(events)})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), ErrTooManyEvents.Error())
+	})
+}
+
+// TestGRPCServer_Limits tests requests beyond the most verified at once are rejected as busy,
+//
+//	and requests taking longer than the request timeout are abandoned.
+func TestGRPCServer_Limits(t *testing.T) {
+
+	service, err := NewService(nil, testServiceOptions())
+	require.NoError(t, err)
+
+	client := newGRPCClient(t, service)
+
+	// every slot is taken by a request being verified
+	service.slots <- struct{}{}
+
+	_, err = client.VerifyEvent(context.Background(), &verificationpb.VerifyEventRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, ErrServiceBusy.Error(), status.Convert(err).Message())
+
+	stream, err := client.VerifyEvents(context.Background(), &verificationpb.VerifyEventsRequest{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	<-service.slots
+
+	t.Run("request timeout", func(t *testing.T) {
+
+		options := testServiceOptions()
+		options.RequestTimeout = 10 * time.Millisecond
+
+		service, err := NewService(nil, options)
+		require.NoError(t, err)
+
+		release := make(chan struct{})
+		defer close(release)
+
+		_, err = service.unaryLimits(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, request any) (any, error) {
+			<-release
+			return nil, nil
+		})
+
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Equal(t, ErrRequestTimeout.Error(), status.Convert(err).Message())
+	})
+}
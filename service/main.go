@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+)
+
+var (
+	// trustedSealFile is the file of the signed log state trusted as the existing log state, the sample signed state if empty
+	trustedSealFile string
+)
+
+// Verification service of datatrails events, and of the consistency of the merklelog, over http and grpc
+//
+// The inclusion, completeness and consistency of the demos are served as a REST API,
+// described by the OpenAPI spec served on /openapi.yaml, and, if -grpc-listen is set,
+// as the grpc API of verificationpb/verification.proto.
+func main() {
+
+	listen := flag.String("listen", ":8080", "address to serve the verification service on")
+	grpcListen := flag.String("grpc-listen", "", "address to serve the grpc api of the verification service on, not served if empty")
+	maxRequestBytes := flag.Int64("max-request-bytes", defaultMaxRequestBytes, "largest request body accepted, in bytes")
+	maxEvents := flag.Int("max-events", defaultMaxEvents, "most events accepted in an event list")
+	maxConcurrent := flag.Int("max-concurrent", defaultMaxConcurrent, "most requests verified at once, any more are rejected as busy")
+	requestTimeout := flag.Duration("request-timeout", defaultRequestTimeout, "how long a request may take before it is abandoned")
+	stateMaxAge := flag.Duration("state-max-age", defaultStateMaxAge, "how long the latest verified log state is served before the newest seal is verified again")
+	flag.StringVar(&trustedSealFile, "trusted-seal", "", "file of the signed log state trusted as the existing log state, e.g. saved earlier, defaults to a sample signed log state of the public tenant")
+	config.Flags(flag.CommandLine)
+	flag.Parse()
+
+	_, err := config.Load(flag.CommandLine, "service")
+	if err != nil {
+		fmt.Printf("Failed to load the config: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = logging.Setup(config.LogLevel, config.LogFormat)
+	if err != nil {
+		fmt.Printf("Failed to set up logging: %v\n", err)
+		os.Exit(1)
+	}
+
+	// the massif readers log to the datatrails logger, which is set up once here, discarding its logs
+	logger.New("NOOP")
+
+	ctx, err := tracing.Setup(context.Background(), "service", config.TraceExporter)
+	if err != nil {
+		slog.Error("failed to set up tracing", logging.KeyError, err)
+		os.Exit(1)
+	}
+	defer tracing.Flush()
+
+	reader, err := merklelog.NewReader(ctx)
+	if err != nil {
+		slog.Error("failed to create the merklelog reader", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	service, err := NewService(reader, ServiceOptions{
+		MaxRequestBytes: *maxRequestBytes,
+		MaxEvents:       *maxEvents,
+		MaxConcurrent:   *maxConcurrent,
+		RequestTimeout:  *requestTimeout,
+		StateMaxAge:     *stateMaxAge,
+	})
+	if err != nil {
+		slog.Error("failed to create the verification service", logging.KeyError, err)
+		tracing.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	servers := 1
+	serveErr := make(chan error, 2)
+
+	slog.Info("serving the verification service", "listen", *listen, logging.KeyTenant, config.TenantID)
+	go func() {
+		serveErr <- Serve(ctx, NewServer(*listen, service))
+	}()
+
+	if *grpcListen != "" {
+
+		servers++
+
+		slog.Info("serving the grpc api of the verification service", "listen", *grpcListen, logging.KeyTenant, config.TenantID)
+		go func() {
+			serveErr <- ServeGRPC(ctx, *grpcListen, NewGRPCServer(service))
+		}()
+	}
+
+	// either server failing stops the other, so the service is never half served
+	failed := false
+	for range servers {
+
+		err := <-serveErr
+		if err != nil {
+			slog.Error("failed to serve the verification service", logging.KeyError, err)
+			failed = true
+			stop()
+		}
+	}
+
+	if failed {
+		tracing.Exit(1)
+	}
+}
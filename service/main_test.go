@@ -0,0 +1,18 @@
+//go:build !azurite
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-common/logger"
+)
+
+// TestMain sets up the datatrails logger the massif readers log to, as main does
+func TestMain(m *testing.M) {
+
+	logger.New("NOOP")
+
+	os.Exit(m.Run())
+}
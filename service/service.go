@@ -0,0 +1,603 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+/**
+ * Service verifies datatrails events, and the consistency of the merklelog, over http,
+ *  so events can be verified without go tooling.
+ *
+ * The routes are:
+ *  1. POST /v1/inclusion an event, as returned by the datatrails events API, to get the verdict of the event.
+ *  2. POST /v1/completeness an event list to get the verdict of each event, and the leaves omitted from the list.
+ *  3. POST /v1/consistency to get the verdict of a new log state, by default the newest seal, verified
+ *     consistent with the trusted log state.
+ *  4. GET /v1/state to get the latest verified log state, the newest seal is verified again once stale.
+ *  5. GET /openapi.yaml to get the OpenAPI spec of the routes, and GET /metrics to get the prometheus metrics.
+ *
+ * Every request is limited in size, in its number of events, in the number of requests verified at once,
+ *  and in how long it may take.
+ */
+
+const (
+	// paths of the routes of the service
+	InclusionPath    = "/v1/inclusion"
+	CompletenessPath = "/v1/completeness"
+	ConsistencyPath  = "/v1/consistency"
+	StatePath        = "/v1/state"
+	OpenAPIPath      = "/openapi.yaml"
+
+	jsonContentType    = "application/json"
+	openAPIContentType = "application/yaml"
+
+	// types of verification of the metrics
+	verificationInclusion    = "inclusion"
+	verificationCompleteness = "completeness"
+	verificationConsistency  = "consistency"
+
+	defaultMaxRequestBytes = 1 << 20
+	defaultMaxEvents       = 1000
+	defaultMaxConcurrent   = 16
+	defaultRequestTimeout  = 30 * time.Second
+	defaultStateMaxAge     = time.Minute
+
+	// timeouts of the server, beyond the timeout of the requests
+	readHeaderTimeout  = 5 * time.Second
+	writeTimeoutMargin = 5 * time.Second
+	idleTimeout        = 2 * time.Minute
+	shutdownTimeout    = 10 * time.Second
+)
+
+var (
+	// openAPISpec is the OpenAPI spec of the routes of the service
+	//
+	//go:embed openapi.yaml
+	openAPISpec []byte
+
+	ErrInvalidServiceOptions = errors.New("invalid service options, every limit must be positive")
+	ErrInvalidRequest        = errors.New("invalid request")
+	ErrRequestTooLarge       = errors.New("request body too large")
+	ErrTooManyEvents         = errors.New("too many events")
+	ErrServiceBusy           = errors.New("too many requests being verified, retry later")
+	ErrRequestTimeout        = errors.New("request timed out")
+)
+
+// ServiceOptions are the limits of the requests of the service
+type ServiceOptions struct {
+
+	// MaxRequestBytes is the largest request body accepted
+	MaxRequestBytes int64
+
+	// MaxEvents is the most events accepted in an event list
+	MaxEvents int
+
+	// MaxConcurrent is the most requests verified at once, any more are rejected as busy
+	MaxConcurrent int
+
+	// RequestTimeout is how long a request may take before it is abandoned
+	RequestTimeout time.Duration
+
+	// StateMaxAge is how long the latest verified log state is served before the newest seal is verified again
+	StateMaxAge time.Duration
+}
+
+// ErrorResponse is the body of a request that failed
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// EventVerdict is the verdict of the verification of an event
+type EventVerdict struct {
+	Identity string `json:"identity"`
+	MMRIndex uint64 `json:"mmr_index"`
+
+	// Included is true if the event is included on the merklelog
+	Included bool `json:"included"`
+
+	// SignedTreeHead is the result of verifying the merklelog_entry.confirm.signed_tree_head
+	SignedTreeHead merklelog.TreeHeadStatus `json:"signed_tree_head,omitempty"`
+
+	// Unequivocal is the result of verifying the signed tree head of the merklelog_entry.unequivocal
+	Unequivocal merklelog.TreeHeadStatus `json:"unequivocal,omitempty"`
+
+	// Verified is true if the event is included, and none of its signed tree heads are invalid or inconsistent
+	Verified bool `json:"verified"`
+
+	// Error is why the event could not be verified, e.g. its massif could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// CompletenessVerdict is the verdict of the verification of an event list
+type CompletenessVerdict struct {
+
+	// Events are the verdicts of each event, in the order of the event list
+	Events []EventVerdict `json:"events"`
+
+	// OmittedLeaves are the mmr indices of the leaves between the first and last event not in the event list
+	OmittedLeaves []uint64 `json:"omitted_leaves"`
+
+	// Complete is true if every event is verified, and no leaves are omitted
+	Complete bool `json:"complete"`
+}
+
+// ConsistencyRequest selects the new log state, the newest seal if neither is set
+type ConsistencyRequest struct {
+	MMRIndex    *uint64 `json:"mmr_index,omitempty"`
+	MassifIndex *uint64 `json:"massif_index,omitempty"`
+}
+
+// LogState is a log state of the merklelog, from a seal
+type LogState struct {
+	MMRSize uint64 `json:"mmr_size"`
+	Root    []byte `json:"root"`
+
+	// Timestamp of the seal, in unix milliseconds
+	Timestamp int64 `json:"timestamp"`
+}
+
+// ConsistencyVerdict is the verdict of the verification of a new log state against the trusted log state
+type ConsistencyVerdict struct {
+	Tenant     string   `json:"tenant"`
+	Trusted    LogState `json:"trusted"`
+	New        LogState `json:"new"`
+	Consistent bool     `json:"consistent"`
+}
+
+// VerifiedState is the latest log state verified consistent with the trusted log state
+type VerifiedState struct {
+	Tenant string `json:"tenant"`
+	LogState
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// Service verifies events, and the consistency of the merklelog, over http
+type Service struct {
+	reader          azblob.Reader
+	options         ServiceOptions
+	verificationKey *ecdsa.PublicKey
+
+	// slots of the requests being verified, one each
+	slots chan struct{}
+
+	// massifCache is shared by every request, of both the http and the grpc api, so each massif is read once,
+	//  and the massif height, if not configured, is discovered from the first massif by the first request that needs it.
+	massifCache *merklelog.MassifCache
+
+	mu sync.Mutex
+
+	// latest is the latest verified log state, nil until a log state is verified
+	latest *VerifiedState
+}
+
+// NewService creates a Service reading the merklelog with the given reader, limited by the given options
+func NewService(reader azblob.Reader, options ServiceOptions) (*Service, error) {
+
+	if options.MaxRequestBytes <= 0 || options.MaxEvents <= 0 || options.MaxConcurrent <= 0 ||
+		options.RequestTimeout <= 0 || options.StateMaxAge <= 0 {
+		return nil, fmt.Errorf("%w: %+v", ErrInvalidServiceOptions, options)
+	}
+
+	verificationKey, err := merklelog.VerificationKeyFromFile(config.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		reader:          reader,
+		options:         options,
+		verificationKey: verificationKey,
+		massifCache:     merklelog.NewMassifCache(context.Background(), reader, config.TenantID, uint8(config.MassifHeight)),
+		slots:           make(chan struct{}, options.MaxConcurrent),
+	}, nil
+}
+
+// Handler gets the http handler of the routes of the service, with the limits of the service
+func (s *Service) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST "+InclusionPath, s.handleInclusion)
+	mux.HandleFunc("POST "+CompletenessPath, s.handleCompleteness)
+	mux.HandleFunc("POST "+ConsistencyPath, s.handleConsistency)
+	mux.HandleFunc("GET "+StatePath, s.handleState)
+	mux.HandleFunc("GET "+OpenAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", openAPIContentType)
+		w.Write(openAPISpec)
+	})
+	mux.Handle("GET "+metrics.Path, metrics.Handler())
+
+	return s.limits(mux)
+}
+
+// limits abandons requests taking longer than the request timeout, and rejects requests
+//
+//	beyond the most verified at once.
+//
+// A request abandoned keeps its slot until its verification returns, so abandoned verifications
+//
+//	can not pile up.
+func (s *Service) limits(next http.Handler) http.Handler {
+
+	timeoutBody, _ := json.Marshal(ErrorResponse{Error: ErrRequestTimeout.Error()})
+
+	limited := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusServiceUnavailable, ErrServiceBusy)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+
+	return http.TimeoutHandler(limited, s.options.RequestTimeout, string(timeoutBody))
+}
+
+// handleInclusion verifies the event of the request
+func (s *Service) handleInclusion(w http.ResponseWriter, r *http.Request) {
+
+	body, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := events.NewEventEntry(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: event: %v", ErrInvalidRequest, err))
+		return
+	}
+
+	ctx, span := tracing.StartSpan(r.Context(), "service.inclusion", attribute.String(logging.KeyEventIdentity, entry.Identity))
+	defer span.End()
+
+	treeHeadVerifier, err := s.newTreeHeadVerifier(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.verifyEvent(ctx, treeHeadVerifier, entry))
+}
+
+// handleCompleteness verifies each event of the event list of the request, and finds the leaves omitted from the list
+func (s *Service) handleCompleteness(w http.ResponseWriter, r *http.Request) {
+
+	body, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := events.NewEventEntries(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: event list: %v", ErrInvalidRequest, err))
+		return
+	}
+
+	if len(entries) > s.options.MaxEvents {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%w: %d, at most %d", ErrTooManyEvents, len(entries), s.options.MaxEvents))
+		return
+	}
+
+	ctx, span := tracing.StartSpan(r.Context(), "service.completeness", attribute.Int("events", len(entries)))
+	defer span.End()
+
+	treeHeadVerifier, err := s.newTreeHeadVerifier(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	verdict := CompletenessVerdict{Events: make([]EventVerdict, 0, len(entries)), Complete: true}
+
+	mmrIndices := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+
+		// the request has been abandoned, so stop verifying the rest of the events
+		if ctx.Err() != nil {
+			return
+		}
+
+		eventVerdict := s.verifyEvent(ctx, treeHeadVerifier, entry)
+		verdict.Events = append(verdict.Events, eventVerdict)
+		verdict.Complete = verdict.Complete && eventVerdict.Verified
+
+		if eventVerdict.Included {
+			mmrIndices = append(mmrIndices, entry.MMRIndex())
+		}
+	}
+
+	verdict.OmittedLeaves = events.OmittedLeaves(mmrIndices)
+	if verdict.OmittedLeaves == nil {
+		verdict.OmittedLeaves = []uint64{}
+	}
+
+	verdict.Complete = verdict.Complete && len(verdict.OmittedLeaves) == 0
+	metrics.Verification(verificationCompleteness, verdict.Complete, nil)
+
+	writeJSON(w, http.StatusOK, verdict)
+}
+
+// handleConsistency verifies the new log state selected by the request is consistent with the trusted log state
+func (s *Service) handleConsistency(w http.ResponseWriter, r *http.Request) {
+
+	body, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	request := ConsistencyRequest{}
+	if len(body) > 0 {
+
+		err := json.Unmarshal(body, &request)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", ErrInvalidRequest, err))
+			return
+		}
+	}
+
+	newState := merklelog.NewStateSelector{MMRIndex: request.MMRIndex, MassifIndex: request.MassifIndex}
+	if newState.MMRIndex != nil && newState.MassifIndex != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", ErrInvalidRequest, merklelog.ErrAmbiguousNewState))
+		return
+	}
+
+	ctx, span := tracing.StartSpan(r.Context(), "service.consistency")
+	defer span.End()
+
+	verdict, err := s.verifyConsistency(ctx, newState)
+	if err != nil {
+		slog.Error("failed to verify the consistency of the log", logging.KeyTenant, config.TenantID, logging.KeyError, err)
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, verdict)
+}
+
+// handleState gets the latest verified log state, verifying the newest seal if there is none, or it is stale
+func (s *Service) handleState(w http.ResponseWriter, r *http.Request) {
+
+	latest := s.latestState()
+	if latest != nil && time.Since(latest.VerifiedAt) < s.options.StateMaxAge {
+		writeJSON(w, http.StatusOK, latest)
+		return
+	}
+
+	ctx, span := tracing.StartSpan(r.Context(), "service.state")
+	defer span.End()
+
+	verdict, err := s.verifyConsistency(ctx, merklelog.NewStateSelector{})
+	if err != nil {
+		slog.Error("failed to verify the consistency of the log", logging.KeyTenant, config.TenantID, logging.KeyError, err)
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	// the newest seal is not consistent with the trusted log state, so there is no latest state to trust
+	if !verdict.Consistent {
+		writeJSON(w, http.StatusConflict, verdict)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.latestState())
+}
+
+// verifyEvent verifies the given event is included on the merklelog, and verifies its signed tree heads
+func (s *Service) verifyEvent(ctx context.Context, treeHeadVerifier *merklelog.TreeHeadVerifier, entry events.EventEntry) EventVerdict {
+
+	verdict := EventVerdict{Identity: entry.Identity, MMRIndex: entry.MMRIndex()}
+
+	massifHeight, err := s.massifCache.MassifHeight()
+	if err != nil {
+		verdict.Error = err.Error()
+		return verdict
+	}
+
+	included, err := events.VerifyEventEntry(ctx, s.reader, massifHeight, entry)
+	metrics.Verification(verificationInclusion, included, err)
+	if err != nil {
+		verdict.Error = err.Error()
+		return verdict
+	}
+
+	verdict.Included = included
+	if !included {
+		return verdict
+	}
+
+	verdict.SignedTreeHead, verdict.Unequivocal, err = merklelog.VerifyTreeHeads(treeHeadVerifier, entry.MerklelogEntry.Confirm, entry.MerklelogEntry.Unequivocal)
+	if err != nil {
+		verdict.Error = err.Error()
+		return verdict
+	}
+
+	verdict.Verified = verdict.SignedTreeHead != merklelog.TreeHeadInvalid && verdict.SignedTreeHead != merklelog.TreeHeadInconsistent &&
+		verdict.Unequivocal != merklelog.TreeHeadInvalid && verdict.Unequivocal != merklelog.TreeHeadInconsistent
+
+	return verdict
+}
+
+// verifyConsistency verifies the selected new log state is consistent with the trusted log state,
+//
+//	the new log state is the latest verified log state if it is consistent, and the newest so far.
+func (s *Service) verifyConsistency(ctx context.Context, newState merklelog.NewStateSelector) (*ConsistencyVerdict, error) {
+
+	trustedLogState, err := merklelog.ExistingSignedState(ctx, trustedSealFile, config.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := massifs.NewRootSignerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	signedState, err := merklelog.NewSignedState(ctx, s.reader, codec, s.massifCache, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	err = merklelog.VerifySignature(ctx, signedState, s.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	logState, err := merklelog.DecodeLogState(ctx, signedState, codec)
+	if err != nil {
+		return nil, err
+	}
+
+	store := merklelog.NewMassifStore(s.massifCache)
+
+	consistent, err := merklelog.NewConsistencyVerifier(ctx, store)(trustedLogState, logState)
+	metrics.Verification(verificationConsistency, consistent, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if consistent {
+		metrics.LatestSeal(logState)
+		s.setLatestState(logState)
+	}
+
+	return &ConsistencyVerdict{
+		Tenant:     config.TenantID,
+		Trusted:    newLogState(trustedLogState),
+		New:        newLogState(logState),
+		Consistent: consistent,
+	}, nil
+}
+
+// newTreeHeadVerifier creates a TreeHeadVerifier of the signed tree heads of the merklelog
+func (s *Service) newTreeHeadVerifier(ctx context.Context) (*merklelog.TreeHeadVerifier, error) {
+
+	massifHeight, err := s.massifCache.MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	return merklelog.NewTreeHeadVerifier(ctx, s.reader, config.TenantID, massifHeight, s.verificationKey)
+}
+
+// latestState gets the latest verified log state, nil if there is none
+func (s *Service) latestState() *VerifiedState {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.latest
+}
+
+// setLatestState sets the given verified log state as the latest, unless a newer log state is verified already
+func (s *Service) setLatestState(logState *massifs.MMRState) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latest != nil && logState.MMRSize < s.latest.MMRSize {
+		return
+	}
+
+	s.latest = &VerifiedState{Tenant: config.TenantID, LogState: newLogState(logState), VerifiedAt: time.Now()}
+}
+
+// readBody reads the body of the request, up to the largest request body accepted,
+//
+//	writing the error response if it can not be read.
+func (s *Service) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.options.MaxRequestBytes))
+	if err == nil {
+		return body, true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%w: at most %d bytes", ErrRequestTooLarge, maxBytesErr.Limit))
+		return nil, false
+	}
+
+	writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", ErrInvalidRequest, err))
+	return nil, false
+}
+
+// newLogState gets the log state of the given mmr state
+func newLogState(mmrState *massifs.MMRState) LogState {
+	return LogState{MMRSize: mmrState.MMRSize, Root: mmrState.Root, Timestamp: mmrState.Timestamp}
+}
+
+// writeJSON writes the given value as the json body of the response, with the given status
+func writeJSON(w http.ResponseWriter, status int, value any) {
+
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes the given error as the json body of the response, with the given status
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+// NewServer creates the http server of the given service on the given address,
+//
+//	whose timeouts bound every request beyond the request timeout of the service.
+func NewServer(address string, service *Service) *http.Server {
+
+	return &http.Server{
+		Addr:              address,
+		Handler:           service.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       service.options.RequestTimeout,
+		WriteTimeout:      service.options.RequestTimeout + writeTimeoutMargin,
+		IdleTimeout:       idleTimeout,
+	}
+}
+
+// Serve serves the given server until the given context is done, then shuts it down,
+//
+//	letting the requests being verified finish.
+func Serve(ctx context.Context, server *http.Server) error {
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
+}
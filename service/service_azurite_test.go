@@ -0,0 +1,316 @@
+//go:build azurite
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/**
+ * Tests of the service against azurite, the local stand-in of the blob storage, started with:
+ *
+ *  task azurite:start
+ *
+ * Azurite is seeded with the first massif, and its seal, of the public tenant. Each is read from its fixture
+ *  in testdata if there is one, or else fetched from the public blob storage, the same blob task azurite:fixtures
+ *  downloads. So with the fixtures committed the tests read nothing from the public blob storage. The fixtures
+ *  are refreshed with:
+ *
+ *  task azurite:fixtures
+ */
+
+const (
+	azuriteURL         = "http://127.0.0.1:10000/devstoreaccount1"
+	azuriteAccountName = "devstoreaccount1"
+	azuriteContainer   = "merklelogs"
+
+	// azuriteAccountKey is the well known key of the azurite storage account, it is not a secret
+	azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+	// massifFixture and sealFixture are the first massif, and its seal, of the public tenant
+	massifFixture = "testdata/massif-0.log"
+	sealFixture   = "testdata/massif-0.sth"
+
+	// fixtureTimeout is how long fetching a fixture from the public blob storage may take
+	fixtureTimeout = time.Minute
+
+	// sampleEvent is the public event of the inclusion demo, included in the first massif of the public tenant
+	sampleEvent = `
+	{
+		"identity": "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601",
+		"asset_identity": "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6",
+		"event_attributes": {
+			"arc_description": "Approving Model",
+			"arc_display_type": "Model Approval",
+			"approvers": "Product Team"
+		},
+		"asset_attributes": {
+			"model_version": "mcbdc.01.0.0",
+			"modelcard_version": "2.0.0",
+			"datacard_version": "2.0.0"
+		},
+		"operation": "Record",
+		"behaviour": "RecordEvidence",
+		"timestamp_declared": "2024-05-07T20:32:00Z",
+		"timestamp_accepted": "2024-05-07T20:32:00Z",
+		"timestamp_committed": "2024-05-07T20:32:27.235Z",
+		"principal_declared": {
+			"issuer": "",
+			"subject": "",
+			"display_name": "",
+			"email": ""
+		},
+		"principal_accepted": {
+			"issuer": "",
+			"subject": "",
+			"display_name": "",
+			"email": ""
+		},
+		"confirmation_status": "CONFIRMED",
+		"transaction_id": "0x224d41c6d984cb67e52274d62a48cd31fce39b6731f25f827d4c59a9cfdff427",
+		"block_number": 7030,
+		"transaction_index": 0,
+		"from": "0x344b47d0FC35a551bd8a7Db4999226C04E764db3",
+		"tenant_identity": "tenant/f023005c-000f-4a57-b2fe-eef425f243ad",
+		"merklelog_entry": {
+			"commit": {
+				"index": "499",
+				"idtimestamp": "018f54c1f0640dca00"
+			},
+			"confirm": {
+				"mmr_size": "501",
+				"root": "AsPmdY7mI1E4Hpkut1e1dYhj+gsRBS2c4NNLvZ0NMBg=",
+				"timestamp": "1715113947353",
+				"idtimestamp": "",
+				"signed_tree_head": ""
+			},
+			"unequivocal": null
+		}
+	}
+	`
+)
+
+// blobBytes is a blob read into memory, to be written to azurite
+type blobBytes struct {
+	*bytes.Reader
+}
+
+// Close the blob, there is nothing to close
+func (bb blobBytes) Close() error {
+	return nil
+}
+
+// TestMain sets up the datatrails logger, as main does, seeds azurite with the fixtures of the first massif,
+//
+//	and its seal, of the public tenant, then points the merklelog settings at azurite.
+func TestMain(m *testing.M) {
+
+	logger.New("NOOP")
+
+	err := seedAzurite(context.Background())
+	if err != nil {
+		fmt.Printf("Failed to seed azurite, is it started with task azurite:start? %v\n", err)
+		os.Exit(1)
+	}
+
+	config.URL, config.Container, config.AccountName, config.AccountKey = azuriteURL, azuriteContainer, azuriteAccountName, azuriteAccountKey
+
+	os.Exit(m.Run())
+}
+
+// seedAzurite writes the first massif, and its seal, of the public tenant to azurite
+func seedAzurite(ctx context.Context) error {
+
+	azurite, err := azblob.NewDev(azblob.DevConfig{AccountName: azuriteAccountName, Key: azuriteAccountKey, URL: azuriteURL}, azuriteContainer)
+	if err != nil {
+		return err
+	}
+
+	fixtures := map[string]string{
+		massifFixture: massifs.TenantMassifBlobPath(config.DefaultTenantID, 0),
+		sealFixture:   massifs.TenantMassifSignedRootPath(config.DefaultTenantID, 0),
+	}
+
+	for fixture, blobPath := range fixtures {
+
+		blob, err := readFixture(ctx, fixture, blobPath)
+		if err != nil {
+			return err
+		}
+
+		_, err = azurite.Put(ctx, blobPath, blobBytes{bytes.NewReader(blob)})
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", blobPath, err)
+		}
+	}
+
+	return nil
+}
+
+// readFixture reads the given fixture of the blob at the given path, or else, if the fixture is not
+//
+//	in testdata, fetches the blob from the public blob storage as task azurite:fixtures does.
+func readFixture(ctx context.Context, fixture string, blobPath string) ([]byte, error) {
+
+	blob, err := os.ReadFile(fixture)
+	if err == nil {
+		return blob, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fixtureTimeout)
+	defer cancel()
+
+	blobURL := strings.Join([]string{config.DefaultURL, config.DefaultContainer, blobPath}, "/")
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, blobURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture %s, and failed to fetch %s: %w", fixture, blobURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("no fixture %s, and failed to fetch %s: %s", fixture, blobURL, response.Status)
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+// newAzuriteServer serves a new service reading the merklelog from azurite
+func newAzuriteServer(t *testing.T) *httptest.Server {
+
+	reader, err := merklelog.NewReader(context.Background())
+	require.NoError(t, err)
+
+	options := testServiceOptions()
+	options.MaxConcurrent = 4
+
+	service, err := NewService(reader, options)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(service.Handler())
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// postJSON posts the given body to the given path of the server, decoding the json response into the given value
+func postJSON(t *testing.T, server *httptest.Server, path string, body string, value any) int {
+
+	response, err := server.Client().Post(server.URL+path, jsonContentType, strings.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.NoError(t, json.NewDecoder(response.Body).Decode(value))
+
+	return response.StatusCode
+}
+
+// TestService_Inclusion tests the verdict of an event included on the merklelog, and of a tampered event
+func TestService_Inclusion(t *testing.T) {
+
+	server := newAzuriteServer(t)
+
+	verdict := EventVerdict{}
+	status := postJSON(t, server, InclusionPath, sampleEvent, &verdict)
+
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, EventVerdict{
+		Identity:       "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601",
+		MMRIndex:       499,
+		Included:       true,
+		SignedTreeHead: merklelog.TreeHeadAbsent,
+		Unequivocal:    merklelog.TreeHeadAbsent,
+		Verified:       true,
+	}, verdict)
+
+	t.Run("tampered event", func(t *testing.T) {
+
+		tamperedEvent := strings.Replace(sampleEvent, "Approving Model", "Rejecting Model", 1)
+
+		verdict := EventVerdict{}
+		status := postJSON(t, server, InclusionPath, tamperedEvent, &verdict)
+
+		require.Equal(t, http.StatusOK, status)
+		assert.False(t, verdict.Included)
+		assert.False(t, verdict.Verified)
+	})
+}
+
+// TestService_Completeness tests the verdict of an event list of a single included event
+func TestService_Completeness(t *testing.T) {
+
+	server := newAzuriteServer(t)
+
+	verdict := CompletenessVerdict{}
+	status := postJSON(t, server, CompletenessPath, `{"events": [`+sampleEvent+`]}`, &verdict)
+
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, verdict.Events, 1)
+	assert.True(t, verdict.Events[0].Verified)
+	assert.Equal(t, []uint64{}, verdict.OmittedLeaves)
+	assert.True(t, verdict.Complete)
+}
+
+// TestService_ConsistencyAndState tests the newest seal is verified consistent with the trusted log state,
+//
+//	then served as the latest verified log state.
+func TestService_ConsistencyAndState(t *testing.T) {
+
+	server := newAzuriteServer(t)
+
+	verdict := ConsistencyVerdict{}
+	status := postJSON(t, server, ConsistencyPath, "", &verdict)
+
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, verdict.Consistent)
+	assert.Equal(t, config.DefaultTenantID, verdict.Tenant)
+	assert.LessOrEqual(t, verdict.Trusted.MMRSize, verdict.New.MMRSize)
+
+	response, err := server.Client().Get(server.URL + StatePath)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	state := VerifiedState{}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&state))
+	assert.Equal(t, verdict.New, state.LogState)
+
+	t.Run("massif not in blob storage", func(t *testing.T) {
+
+		errorResponse := ErrorResponse{}
+		status := postJSON(t, server, ConsistencyPath, `{"massif_index": 1}`, &errorResponse)
+
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.NotEmpty(t, errorResponse.Error)
+	})
+}
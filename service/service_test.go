@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// testServiceOptions are the limits of the service under test
+func testServiceOptions() ServiceOptions {
+	return ServiceOptions{
+		MaxRequestBytes: 1024,
+		MaxEvents:       2,
+		MaxConcurrent:   1,
+		RequestTimeout:  time.Minute,
+		StateMaxAge:     time.Minute,
+	}
+}
+
+// decodeError decodes the error of the body of the given failed response
+func decodeError(t *testing.T, recorder *httptest.ResponseRecorder) string {
+
+	errorResponse := ErrorResponse{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &errorResponse))
+
+	return errorResponse.Error
+}
+
+// TestNewService_Invalid tests every limit of the service must be positive
+func TestNewService_Invalid(t *testing.T) {
+
+	options := testServiceOptions()
+	options.MaxEvents = 0
+
+	_, err := NewService(nil, options)
+	assert.ErrorIs(t, err, ErrInvalidServiceOptions)
+}
+
+// TestService_InvalidRequests tests requests that are invalid, or beyond the limits of the service,
+//
+//	are rejected before the merklelog is read.
+func TestService_InvalidRequests(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+		err      string
+	}{
+		{
+			name:     "event not json",
+			method:   http.MethodPost,
+			path:     InclusionPath,
+			body:     "not an event",
+			expected: http.StatusBadRequest,
+			err:      ErrInvalidRequest.Error(),
+		},
+		{
+			name:     "event too large",
+			method:   http.MethodPost,
+			path:     InclusionPath,
+			body:     `{"identity": "` + strings.Repeat("a", 1024) + `"}`,
+			expected: http.StatusRequestEntityTooLarge,
+			err:      ErrRequestTooLarge.Error(),
+		},
+		{
+			name:     "event list not json",
+			method:   http.MethodPost,
+			path:     CompletenessPath,
+			body:     `{"events": {}}`,
+			expected: http.StatusBadRequest,
+			err:      ErrInvalidRequest.Error(),
+		},
+		{
+			name:     "too many events",
+			method:   http.MethodPost,
+			path:     CompletenessPath,
+			body:     `{"events": [{"identity": "a"}, {"identity": "b"}, {"identity": "c"}]}`,
+			expected: http.StatusRequestEntityTooLarge,
+			err:      ErrTooManyEvents.Error(),
+		},
+		{
+			name:     "ambiguous new state",
+			method:   http.MethodPost,
+			path:     ConsistencyPath,
+			body:     `{"mmr_index": 830, "massif_index": 0}`,
+			expected: http.StatusBadRequest,
+			err:      merklelog.ErrAmbiguousNewState.Error(),
+		},
+		{
+			name:     "consistency request not json",
+			method:   http.MethodPost,
+			path:     ConsistencyPath,
+			body:     `{"mmr_index": "latest"}`,
+			expected: http.StatusBadRequest,
+			err:      ErrInvalidRequest.Error(),
+		},
+	}
+
+	service, err := NewService(nil, testServiceOptions())
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			recorder := httptest.NewRecorder()
+			service.Handler().ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+
+			assert.Equal(t, test.expected, recorder.Code)
+			assert.Equal(t, jsonContentType, recorder.Header().Get("Content-Type"))
+			assert.Contains(t, decodeError(t, recorder), test.err)
+		})
+	}
+
+	t.Run("not a post", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+		service.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, InclusionPath, nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
+
+// TestService_Limits tests requests beyond the most verified at once are rejected as busy,
+//
+//	and requests taking longer than the request timeout are abandoned.
+func TestService_Limits(t *testing.T) {
+
+	service, err := NewService(nil, testServiceOptions())
+	require.NoError(t, err)
+
+	started, release, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	blocking := service.limits(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	go func() {
+		blocking.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, StatePath, nil))
+		close(done)
+	}()
+	<-started
+
+	recorder := httptest.NewRecorder()
+	blocking.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, StatePath, nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	assert.Equal(t, ErrServiceBusy.Error(), decodeError(t, recorder))
+
+	close(release)
+	<-done
+
+	t.Run("request timeout", func(t *testing.T) {
+
+		options := testServiceOptions()
+		options.RequestTimeout = 10 * time.Millisecond
+
+		service, err := NewService(nil, options)
+		require.NoError(t, err)
+
+		slow := service.limits(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+
+		recorder := httptest.NewRecorder()
+		slow.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, StatePath, nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, ErrRequestTimeout.Error(), decodeError(t, recorder))
+	})
+}
+
+// TestService_OpenAPI tests the OpenAPI spec is served, and describes every route of the service
+func TestService_OpenAPI(t *testing.T) {
+
+	service, err := NewService(nil, testServiceOptions())
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	service.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, OpenAPIPath, nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, openAPIContentType, recorder.Header().Get("Content-Type"))
+
+	spec := struct {
+		OpenAPI string                    `yaml:"openapi"`
+		Paths   map[string]map[string]any `yaml:"paths"`
+	}{}
+	require.NoError(t, yaml.Unmarshal(recorder.Body.Bytes(), &spec))
+
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+
+	routes := map[string]string{
+		InclusionPath:    "post",
+		CompletenessPath: "post",
+		ConsistencyPath:  "post",
+		StatePath:        "get",
+		OpenAPIPath:      "get",
+		metrics.Path:     "get",
+	}
+	assert.Len(t, spec.Paths, len(routes))
+
+	for path, method := range routes {
+		assert.Contains(t, spec.Paths[path], method, path)
+	}
+}
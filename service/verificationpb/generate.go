@@ -0,0 +1,5 @@
+// Package verificationpb is the grpc api of the verification service, generated from verification.proto
+// by task gobuild:go:generate.
+package verificationpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative verification.proto
@@ -0,0 +1,754 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: verification.proto
+
+package verificationpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TreeHeadStatus is the result of verifying a signed tree head of an event.
+type TreeHeadStatus int32
+
+const (
+	TreeHeadStatus_TREE_HEAD_STATUS_UNSPECIFIED TreeHeadStatus = 0
+	// The event has no signed tree head, so there is nothing to verify.
+	TreeHeadStatus_TREE_HEAD_STATUS_ABSENT TreeHeadStatus = 1
+	// The signed tree head is signed by datatrails, matches the confirmed root,
+	// and is consistent with the sealed log state.
+	TreeHeadStatus_TREE_HEAD_STATUS_VERIFIED TreeHeadStatus = 2
+	// The signed tree head is not a COSE Sign1 message signed by datatrails.
+	TreeHeadStatus_TREE_HEAD_STATUS_INVALID TreeHeadStatus = 3
+	// The signed tree head does not match the confirmed root, or is not consistent with the sealed log state.
+	TreeHeadStatus_TREE_HEAD_STATUS_INCONSISTENT TreeHeadStatus = 4
+)
+
+// Enum value maps for TreeHeadStatus.
+var (
+	TreeHeadStatus_name = map[int32]string{
+		0: "TREE_HEAD_STATUS_UNSPECIFIED",
+		1: "TREE_HEAD_STATUS_ABSENT",
+		2: "TREE_HEAD_STATUS_VERIFIED",
+		3: "TREE_HEAD_STATUS_INVALID",
+		4: "TREE_HEAD_STATUS_INCONSISTENT",
+	}
+	TreeHeadStatus_value = map[string]int32{
+		"TREE_HEAD_STATUS_UNSPECIFIED":  0,
+		"TREE_HEAD_STATUS_ABSENT":       1,
+		"TREE_HEAD_STATUS_VERIFIED":     2,
+		"TREE_HEAD_STATUS_INVALID":      3,
+		"TREE_HEAD_STATUS_INCONSISTENT": 4,
+	}
+)
+
+func (x TreeHeadStatus) Enum() *TreeHeadStatus {
+	p := new(TreeHeadStatus)
+	*p = x
+	return p
+}
+
+func (x TreeHeadStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TreeHeadStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_verification_proto_enumTypes[0].Descriptor()
+}
+
+func (TreeHeadStatus) Type() protoreflect.EnumType {
+	return &file_verification_proto_enumTypes[0]
+}
+
+func (x TreeHeadStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TreeHeadStatus.Descriptor instead.
+func (TreeHeadStatus) EnumDescriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{0}
+}
+
+// VerifyEventRequest is an event to verify.
+type VerifyEventRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The event, as json returned by the datatrails events API.
+	EventJson []byte `protobuf:"bytes,1,opt,name=event_json,json=eventJson,proto3" json:"event_json,omitempty"`
+}
+
+func (x *VerifyEventRequest) Reset() {
+	*x = VerifyEventRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_verification_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEventRequest) ProtoMessage() {}
+
+func (x *VerifyEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_verification_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEventRequest.ProtoReflect.Descriptor instead.
+func (*VerifyEventRequest) Descriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *VerifyEventRequest) GetEventJson() []byte {
+	if x != nil {
+		return x.EventJson
+	}
+	return nil
+}
+
+// VerifyEventsRequest is an event list to verify.
+type VerifyEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The event list, as json returned by the datatrails events API.
+	EventsJson []byte `protobuf:"bytes,1,opt,name=events_json,json=eventsJson,proto3" json:"events_json,omitempty"`
+}
+
+func (x *VerifyEventsRequest) Reset() {
+	*x = VerifyEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_verification_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyEventsRequest) ProtoMessage() {}
+
+func (x *VerifyEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_verification_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyEventsRequest.ProtoReflect.Descriptor instead.
+func (*VerifyEventsRequest) Descriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VerifyEventsRequest) GetEventsJson() []byte {
+	if x != nil {
+		return x.EventsJson
+	}
+	return nil
+}
+
+// EventVerdict is the verdict of the verification of an event.
+type EventVerdict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Identity string `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	MmrIndex uint64 `protobuf:"varint,2,opt,name=mmr_index,json=mmrIndex,proto3" json:"mmr_index,omitempty"`
+	// The event is included on the merklelog.
+	Included bool `protobuf:"varint,3,opt,name=included,proto3" json:"included,omitempty"`
+	// The result of verifying the merklelog_entry.confirm.signed_tree_head.
+	SignedTreeHead TreeHeadStatus `protobuf:"varint,4,opt,name=signed_tree_head,json=signedTreeHead,proto3,enum=datatrails.verification.v1.TreeHeadStatus" json:"signed_tree_head,omitempty"`
+	// The result of verifying the signed tree head of the merklelog_entry.unequivocal.
+	Unequivocal TreeHeadStatus `protobuf:"varint,5,opt,name=unequivocal,proto3,enum=datatrails.verification.v1.TreeHeadStatus" json:"unequivocal,omitempty"`
+	// The event is included, and none of its signed tree heads are invalid or inconsistent.
+	Verified bool `protobuf:"varint,6,opt,name=verified,proto3" json:"verified,omitempty"`
+	// Why the event could not be verified, e.g. its massif could not be read.
+	Error string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	// The index of the event in the event list, as the verdicts of VerifyEvents are returned as they complete.
+	Index uint32 `protobuf:"varint,8,opt,name=index,proto3" json:"index,omitempty"`
+}
+
+func (x *EventVerdict) Reset() {
+	*x = EventVerdict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_verification_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventVerdict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventVerdict) ProtoMessage() {}
+
+func (x *EventVerdict) ProtoReflect() protoreflect.Message {
+	mi := &file_verification_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventVerdict.ProtoReflect.Descriptor instead.
+func (*EventVerdict) Descriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EventVerdict) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *EventVerdict) GetMmrIndex() uint64 {
+	if x != nil {
+		return x.MmrIndex
+	}
+	return 0
+}
+
+func (x *EventVerdict) GetIncluded() bool {
+	if x != nil {
+		return x.Included
+	}
+	return false
+}
+
+func (x *EventVerdict) GetSignedTreeHead() TreeHeadStatus {
+	if x != nil {
+		return x.SignedTreeHead
+	}
+	return TreeHeadStatus_TREE_HEAD_STATUS_UNSPECIFIED
+}
+
+func (x *EventVerdict) GetUnequivocal() TreeHeadStatus {
+	if x != nil {
+		return x.Unequivocal
+	}
+	return TreeHeadStatus_TREE_HEAD_STATUS_UNSPECIFIED
+}
+
+func (x *EventVerdict) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *EventVerdict) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *EventVerdict) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+// VerifyConsistencyRequest selects the new log state, the newest seal if neither is set.
+type VerifyConsistencyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to NewState:
+	//	*VerifyConsistencyRequest_MmrIndex
+	//	*VerifyConsistencyRequest_MassifIndex
+	NewState isVerifyConsistencyRequest_NewState `protobuf_oneof:"new_state"`
+}
+
+func (x *VerifyConsistencyRequest) Reset() {
+	*x = VerifyConsistencyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_verification_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyConsistencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyConsistencyRequest) ProtoMessage() {}
+
+func (x *VerifyConsistencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_verification_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyConsistencyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyConsistencyRequest) Descriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{3}
+}
+
+func (m *VerifyConsistencyRequest) GetNewState() isVerifyConsistencyRequest_NewState {
+	if m != nil {
+		return m.NewState
+	}
+	return nil
+}
+
+func (x *VerifyConsistencyRequest) GetMmrIndex() uint64 {
+	if x, ok := x.GetNewState().(*VerifyConsistencyRequest_MmrIndex); ok {
+		return x.MmrIndex
+	}
+	return 0
+}
+
+func (x *VerifyConsistencyRequest) GetMassifIndex() uint64 {
+	if x, ok := x.GetNewState().(*VerifyConsistencyRequest_MassifIndex); ok {
+		return x.MassifIndex
+	}
+	return 0
+}
+
+type isVerifyConsistencyRequest_NewState interface {
+	isVerifyConsistencyRequest_NewState()
+}
+
+type VerifyConsistencyRequest_MmrIndex struct {
+	// Selects the seal of the massif holding the mmr index.
+	MmrIndex uint64 `protobuf:"varint,1,opt,name=mmr_index,json=mmrIndex,proto3,oneof"`
+}
+
+type VerifyConsistencyRequest_MassifIndex struct {
+	// Selects the seal of the massif.
+	MassifIndex uint64 `protobuf:"varint,2,opt,name=massif_index,json=massifIndex,proto3,oneof"`
+}
+
+func (*VerifyConsistencyRequest_MmrIndex) isVerifyConsistencyRequest_NewState() {}
+
+func (*VerifyConsistencyRequest_MassifIndex) isVerifyConsistencyRequest_NewState() {}
+
+// LogState is a log state of the merklelog, from a seal.
+type LogState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MmrSize uint64 `protobuf:"varint,1,opt,name=mmr_size,json=mmrSize,proto3" json:"mmr_size,omitempty"`
+	Root    []byte `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	// The timestamp of the seal, in unix milliseconds.
+	Timestamp int64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *LogState) Reset() {
+	*x = LogState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_verification_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogState) ProtoMessage() {}
+
+func (x *LogState) ProtoReflect() protoreflect.Message {
+	mi := &file_verification_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogState.ProtoReflect.Descriptor instead.
+func (*LogState) Descriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LogState) GetMmrSize() uint64 {
+	if x != nil {
+		return x.MmrSize
+	}
+	return 0
+}
+
+func (x *LogState) GetRoot() []byte {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+func (x *LogState) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// ConsistencyVerdict is the verdict of the verification of a new log state against the trusted log state.
+type ConsistencyVerdict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tenant     string    `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Trusted    *LogState `protobuf:"bytes,2,opt,name=trusted,proto3" json:"trusted,omitempty"`
+	New        *LogState `protobuf:"bytes,3,opt,name=new,proto3" json:"new,omitempty"`
+	Consistent bool      `protobuf:"varint,4,opt,name=consistent,proto3" json:"consistent,omitempty"`
+}
+
+func (x *ConsistencyVerdict) Reset() {
+	*x = ConsistencyVerdict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_verification_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConsistencyVerdict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsistencyVerdict) ProtoMessage() {}
+
+func (x *ConsistencyVerdict) ProtoReflect() protoreflect.Message {
+	mi := &file_verification_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsistencyVerdict.ProtoReflect.Descriptor instead.
+func (*ConsistencyVerdict) Descriptor() ([]byte, []int) {
+	return file_verification_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ConsistencyVerdict) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *ConsistencyVerdict) GetTrusted() *LogState {
+	if x != nil {
+		return x.Trusted
+	}
+	return nil
+}
+
+func (x *ConsistencyVerdict) GetNew() *LogState {
+	if x != nil {
+		return x.New
+	}
+	return nil
+}
+
+func (x *ConsistencyVerdict) GetConsistent() bool {
+	if x != nil {
+		return x.Consistent
+	}
+	return false
+}
+
+var File_verification_proto protoreflect.FileDescriptor
+
+var file_verification_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1a, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73,
+	0x2e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x22, 0x33, 0x0a, 0x12, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f,
+	0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x4a, 0x73, 0x6f, 0x6e, 0x22, 0x36, 0x0a, 0x13, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0a, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x4a, 0x73, 0x6f, 0x6e, 0x22, 0xcf, 0x02,
+	0x0a, 0x0c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x6d,
+	0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6d,
+	0x6d, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x64, 0x12, 0x54, 0x0a, 0x10, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x5f, 0x74, 0x72,
+	0x65, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65, 0x48,
+	0x65, 0x61, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0e, 0x73, 0x69, 0x67, 0x6e, 0x65,
+	0x64, 0x54, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x12, 0x4c, 0x0a, 0x0b, 0x75, 0x6e, 0x65,
+	0x71, 0x75, 0x69, 0x76, 0x6f, 0x63, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x65, 0x65,
+	0x48, 0x65, 0x61, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x0b, 0x75, 0x6e, 0x65, 0x71,
+	0x75, 0x69, 0x76, 0x6f, 0x63, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x76, 0x65, 0x72, 0x69, 0x66,
+	0x69, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22,
+	0x6b, 0x0a, 0x18, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74,
+	0x65, 0x6e, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x6d,
+	0x6d, 0x72, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x48, 0x00,
+	0x52, 0x08, 0x6d, 0x6d, 0x72, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x23, 0x0a, 0x0c, 0x6d, 0x61,
+	0x73, 0x73, 0x69, 0x66, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x48, 0x00, 0x52, 0x0b, 0x6d, 0x61, 0x73, 0x73, 0x69, 0x66, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x42,
+	0x0b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x57, 0x0a, 0x08,
+	0x4c, 0x6f, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x6d, 0x72, 0x5f,
+	0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x6d, 0x6d, 0x72, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0xc4, 0x01, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x73, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65,
+	0x6e, 0x61, 0x6e, 0x74, 0x12, 0x3e, 0x0a, 0x07, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69,
+	0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x07, 0x74, 0x72, 0x75,
+	0x73, 0x74, 0x65, 0x64, 0x12, 0x36, 0x0a, 0x03, 0x6e, 0x65, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x24, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76,
+	0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4c,
+	0x6f, 0x67, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x03, 0x6e, 0x65, 0x77, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x74, 0x2a, 0xaf, 0x01, 0x0a,
+	0x0e, 0x54, 0x72, 0x65, 0x65, 0x48, 0x65, 0x61, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x20, 0x0a, 0x1c, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x48, 0x45, 0x41, 0x44, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x1b, 0x0a, 0x17, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x48, 0x45, 0x41, 0x44, 0x5f, 0x53,
+	0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x41, 0x42, 0x53, 0x45, 0x4e, 0x54, 0x10, 0x01, 0x12, 0x1d,
+	0x0a, 0x19, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x48, 0x45, 0x41, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54,
+	0x55, 0x53, 0x5f, 0x56, 0x45, 0x52, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x02, 0x12, 0x1c, 0x0a,
+	0x18, 0x54, 0x52, 0x45, 0x45, 0x5f, 0x48, 0x45, 0x41, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x03, 0x12, 0x21, 0x0a, 0x1d, 0x54,
+	0x52, 0x45, 0x45, 0x5f, 0x48, 0x45, 0x41, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x49, 0x4e, 0x43, 0x4f, 0x4e, 0x53, 0x49, 0x53, 0x54, 0x45, 0x4e, 0x54, 0x10, 0x04, 0x32, 0xdf,
+	0x02, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x67, 0x0a, 0x0b, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2e,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x6b, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x79, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x2f, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74,
+	0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x64, 0x61, 0x74, 0x61,
+	0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x56, 0x65, 0x72, 0x64,
+	0x69, 0x63, 0x74, 0x30, 0x01, 0x12, 0x79, 0x0a, 0x11, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43,
+	0x6f, 0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x34, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x43, 0x6f,
+	0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x2e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2e, 0x76, 0x65,
+	0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
+	0x6e, 0x73, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74,
+	0x42, 0x42, 0x5a, 0x40, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x64,
+	0x61, 0x74, 0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2f, 0x67, 0x6f, 0x2d, 0x64, 0x61, 0x74,
+	0x61, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x73, 0x2d, 0x64, 0x65, 0x6d, 0x6f, 0x73, 0x2f, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_verification_proto_rawDescOnce sync.Once
+	file_verification_proto_rawDescData = file_verification_proto_rawDesc
+)
+
+func file_verification_proto_rawDescGZIP() []byte {
+	file_verification_proto_rawDescOnce.Do(func() {
+		file_verification_proto_rawDescData = protoimpl.X.CompressGZIP(file_verification_proto_rawDescData)
+	})
+	return file_verification_proto_rawDescData
+}
+
+var file_verification_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_verification_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_verification_proto_goTypes = []any{
+	(TreeHeadStatus)(0),              // 0: datatrails.verification.v1.TreeHeadStatus
+	(*VerifyEventRequest)(nil),       // 1: datatrails.verification.v1.VerifyEventRequest
+	(*VerifyEventsRequest)(nil),      // 2: datatrails.verification.v1.VerifyEventsRequest
+	(*EventVerdict)(nil),             // 3: datatrails.verification.v1.EventVerdict
+	(*VerifyConsistencyRequest)(nil), // 4: datatrails.verification.v1.VerifyConsistencyRequest
+	(*LogState)(nil),                 // 5: datatrails.verification.v1.LogState
+	(*ConsistencyVerdict)(nil),       // 6: datatrails.verification.v1.ConsistencyVerdict
+}
+var file_verification_proto_depIdxs = []int32{
+	0, // 0: datatrails.verification.v1.EventVerdict.signed_tree_head:type_name -> datatrails.verification.v1.TreeHeadStatus
+	0, // 1: datatrails.verification.v1.EventVerdict.unequivocal:type_name -> datatrails.verification.v1.TreeHeadStatus
+	5, // 2: datatrails.verification.v1.ConsistencyVerdict.trusted:type_name -> datatrails.verification.v1.LogState
+	5, // 3: datatrails.verification.v1.ConsistencyVerdict.new:type_name -> datatrails.verification.v1.LogState
+	1, // 4: datatrails.verification.v1.Verification.VerifyEvent:input_type -> datatrails.verification.v1.VerifyEventRequest
+	2, // 5: datatrails.verification.v1.Verification.VerifyEvents:input_type -> datatrails.verification.v1.VerifyEventsRequest
+	4, // 6: datatrails.verification.v1.Verification.VerifyConsistency:input_type -> datatrails.verification.v1.VerifyConsistencyRequest
+	3, // 7: datatrails.verification.v1.Verification.VerifyEvent:output_type -> datatrails.verification.v1.EventVerdict
+	3, // 8: datatrails.verification.v1.Verification.VerifyEvents:output_type -> datatrails.verification.v1.EventVerdict
+	6, // 9: datatrails.verification.v1.Verification.VerifyConsistency:output_type -> datatrails.verification.v1.ConsistencyVerdict
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_verification_proto_init() }
+func file_verification_proto_init() {
+	if File_verification_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_verification_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*VerifyEventRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_verification_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*VerifyEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_verification_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*EventVerdict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_verification_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*VerifyConsistencyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_verification_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*LogState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_verification_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ConsistencyVerdict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_verification_proto_msgTypes[3].OneofWrappers = []any{
+		(*VerifyConsistencyRequest_MmrIndex)(nil),
+		(*VerifyConsistencyRequest_MassifIndex)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_verification_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_verification_proto_goTypes,
+		DependencyIndexes: file_verification_proto_depIdxs,
+		EnumInfos:         file_verification_proto_enumTypes,
+		MessageInfos:      file_verification_proto_msgTypes,
+	}.Build()
+	File_verification_proto = out.File
+	file_verification_proto_rawDesc = nil
+	file_verification_proto_goTypes = nil
+	file_verification_proto_depIdxs = nil
+}
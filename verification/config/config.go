@@ -0,0 +1,625 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"gopkg.in/yaml.v3"
+)
+
+/**
+ * Config holds the settings of the demos, every one of which is a flag.
+ *
+ * Each setting is taken from, in order of precedence:
+ *
+ *  1. its flag, e.g. -tenant
+ *  2. its DATATRAILS_* environment variable, e.g. DATATRAILS_TENANT
+ *  3. the profile selected by -profile, or DATATRAILS_PROFILE, of the config file
+ *  4. the YAML config file given by -config, or DATATRAILS_CONFIG, keyed by the flag name, e.g. tenant
+ *  5. its default
+ *
+ * The same config file is shared by all the demos. Only the settings shared by all the demos, registered by
+ *  Flags, are at its top level. The settings of only one demo are in the section of that demo, e.g. consistency,
+ *  and take precedence over the shared settings, so a setting of one demo is never unknown to the others.
+ *
+ * Each named profile of the config file, e.g. for each tenant verified, holds settings the same way,
+ *  with its own tenant, blob storage, credentials, keys and state directories.
+ *
+ * The storage account key of a private tenant is a secret, so it is never a flag or in the config file,
+ *  which would expose it in the process list or in version control. It is only read from DATATRAILS_ACCOUNT_KEY,
+ *  or else the file given by -account-key-file, which only the verifier need be able to read.
+ */
+
+const (
+	configEnvPrefix = "DATATRAILS_"
+	configFlag      = "config"
+	profileFlag     = "profile"
+	profilesKey     = "profiles"
+	accountKeyName  = "account-key"
+
+	// maxMassifHeight is the highest massif height, massifs of any greater height could not be indexed by a uint64 mmr index
+	maxMassifHeight = 64
+
+	// defaults of the settings of the merklelog, of the datatrails public tenant
+	DefaultTenantID            = "tenant/6ea5cd00-c711-3649-6914-7b125928bbb4"
+	DefaultURL                 = "https://app.datatrails.ai/verifiabledata"
+	DefaultContainer           = "merklelogs"
+	DefaultVerificationKeyFile = "verificationkey.pem"
+	DefaultLogLevel            = "info"
+
+	tenantPrefix = "tenant/"
+)
+
+var (
+	// demos each have a section of the config file, of the settings only that demo has flags for
+	demos = []string{"inclusion", "completeness", "consistency", "service"}
+
+	// sharedSettings are the settings registered by Flags, the only settings at the top level of the config file
+	sharedSettings = []string{
+		"tenant", "url", "container", "account-name", "account-key-file", "verification-key",
+		"log-level", "log-format", "trace-exporter", "massif-height",
+	}
+
+	// TenantID is the tenant of the merklelog verified, the datatrails public tenant by default
+	TenantID = DefaultTenantID
+
+	// merklelog reader configuration
+	URL       = DefaultURL
+	Container = DefaultContainer
+
+	// credentials of the blob storage of private tenants, the merklelog is read anonymously if there is no key
+	AccountName    string
+	AccountKeyFile string
+
+	// AccountKey is the storage account key, read from DATATRAILS_ACCOUNT_KEY or the AccountKeyFile, never a flag
+	AccountKey string
+
+	// VerificationKeyFile is the pem file of the key the seals of the merklelog are verified with
+	VerificationKeyFile = DefaultVerificationKeyFile
+
+	// LogLevel and LogFormat are the level and format of the logs
+	LogLevel  = DefaultLogLevel
+	LogFormat = logging.FormatText
+
+	// TraceExporter is the exporter of the spans of the verification steps, none by default
+	TraceExporter = tracing.ExporterNone
+
+	// MassifHeight is the height of the massifs of the merklelog, discovered from the first massif if 0
+	MassifHeight uint
+
+	ErrUnknownSetting = errors.New("unknown setting")
+	ErrInvalidSetting = errors.New("invalid setting")
+	ErrUnknownProfile = errors.New("unknown profile")
+)
+
+// Config is the config file of a demo, loaded onto the flags of the demo
+type Config struct {
+	fs   *flag.FlagSet
+	demo string
+	path string
+
+	// values of the config file, other than the profiles
+	values map[string]any
+
+	// profiles of the config file, by name
+	profiles map[string]map[string]any
+
+	// commandLine flags take precedence over everything else
+	commandLine map[string]bool
+}
+
+// Flags registers the flags of the settings shared by all the demos, of the merklelog, its logs and traces,
+//
+//	and of the config file.
+func Flags(fs *flag.FlagSet) {
+
+	fs.StringVar(&TenantID, "tenant", DefaultTenantID, "tenant of the merklelog to verify, e.g. tenant/<uuid>")
+	fs.StringVar(&URL, "url", DefaultURL, "base url of the blob storage of the merklelog")
+	fs.StringVar(&Container, "container", DefaultContainer, "container of the merklelog in the blob storage")
+	fs.StringVar(&AccountName, "account-name", "", "storage account name of the blob storage of a private tenant")
+	fs.StringVar(&AccountKeyFile, "account-key-file", "", "file of the storage account key of the blob storage of a private tenant, DATATRAILS_ACCOUNT_KEY takes precedence, the merklelog is read anonymously if there is no key")
+	fs.StringVar(&VerificationKeyFile, "verification-key", DefaultVerificationKeyFile, "pem file of the key the seals of the merklelog are verified with")
+	fs.StringVar(&LogLevel, "log-level", DefaultLogLevel, "level of the logs, either debug, info, warn or error")
+	fs.StringVar(&LogFormat, "log-format", logging.FormatText, "format of the logs, either text or json")
+	fs.StringVar(&TraceExporter, "trace-exporter", tracing.ExporterNone, "exporter of the spans of the verification steps, either stdout, or otlp configured by the OTEL_EXPORTER_OTLP_* environment variables, none if empty")
+	fs.UintVar(&MassifHeight, "massif-height", 0, "height of the massifs of the merklelog, discovered from the first massif if 0")
+
+	fs.String(configFlag, "", "YAML config file of the settings, keyed by flag name, overridden by DATATRAILS_* environment variables and flags")
+	fs.String(profileFlag, "", "profile of the config file to take the settings from, e.g. for each tenant verified")
+}
+
+// Load reads the config file of the given demo's flag set, then loads the selected profile, if any
+func Load(fs *flag.FlagSet, demo string) (*Config, error) {
+
+	config, err := New(fs, demo)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Load(config.Profile())
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// New reads the config file given by the -config flag of the given demo's flag set,
+//
+//	or DATATRAILS_CONFIG, once the command line is parsed.
+func New(fs *flag.FlagSet, demo string) (*Config, error) {
+
+	config := &Config{
+		fs:          fs,
+		demo:        demo,
+		values:      map[string]any{},
+		profiles:    map[string]map[string]any{},
+		commandLine: map[string]bool{},
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		config.commandLine[f.Name] = true
+	})
+
+	config.path = config.selected(configFlag)
+	if config.path == "" {
+		return config, nil
+	}
+
+	configYaml, err := os.ReadFile(config.path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(configYaml, &config.values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", config.path, err)
+	}
+
+	profiles, ok := config.values[profilesKey]
+	if !ok {
+		return config, nil
+	}
+
+	delete(config.values, profilesKey)
+
+	profileValues, ok := profiles.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in %s, expected a section of profiles", ErrInvalidSetting, profilesKey, config.path)
+	}
+
+	for name, values := range profileValues {
+
+		config.profiles[name], ok = values.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: profile %s in %s, expected a section of settings", ErrInvalidSetting, name, config.path)
+		}
+	}
+
+	return config, nil
+}
+
+// Profile gets the profile selected by the -profile flag, or DATATRAILS_PROFILE, or the config file, if any
+func (c *Config) Profile() string {
+	return c.selected(profileFlag)
+}
+
+// Profiles gets the names of the profiles of the config file, in name order
+func (c *Config) Profiles() []string {
+
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Load sets every flag, not set on the command line, from its environment variable,
+//
+//	or else the given profile, or else the config file, or else its default,
+//	then validates the settings of the merklelog.
+//
+// Loading another profile replaces all the settings of the previous profile.
+func (c *Config) Load(profile string) error {
+
+	settings, err := c.Settings(profile)
+	if err != nil {
+		return err
+	}
+
+	err = settings.Set(c.fs)
+	if err != nil {
+		return err
+	}
+
+	AccountKey = settings.AccountKey
+
+	return nil
+}
+
+// Settings gets the settings of the given profile, from the command line, or else their environment variables,
+//
+//	or else the profile, or else the config file, or else their defaults, then validates the settings of the merklelog.
+//
+// Unlike Load, neither the flags nor the shared settings are set, so the settings of several profiles may be used at once.
+func (c *Config) Settings(profile string) (*Settings, error) {
+
+	fileSettings, err := c.settings(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &Settings{values: map[string]configSetting{}}
+	c.fs.VisitAll(func(f *flag.Flag) {
+
+		if f.Name == configFlag || f.Name == profileFlag {
+			return
+		}
+
+		if c.commandLine[f.Name] {
+			settings.values[f.Name] = configSetting{value: f.Value.String(), source: "command line"}
+			return
+		}
+
+		envName := configEnvName(f.Name)
+		if value, ok := os.LookupEnv(envName); ok {
+			settings.values[f.Name] = configSetting{value: value, source: envName}
+			return
+		}
+
+		setting, ok := fileSettings[f.Name]
+		if !ok {
+			setting = configSetting{value: f.DefValue, source: "default"}
+		}
+
+		settings.values[f.Name] = setting
+	})
+
+	err = settings.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = settings.loadAccountKey()
+	if err != nil {
+		return nil, err
+	}
+
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// Settings are the settings of the merklelog of one profile, and the value of every other setting of the demo,
+//
+//	resolved the same way as the flags, but held apart from the flags and the shared settings.
+type Settings struct {
+	TenantID            string
+	URL                 string
+	Container           string
+	AccountName         string
+	AccountKeyFile      string
+	AccountKey          string
+	VerificationKeyFile string
+	MassifHeight        uint
+
+	// values of every setting, keyed by flag name
+	values map[string]configSetting
+}
+
+// Current gets the shared settings of the merklelog, as set by the flags or Load
+func Current() *Settings {
+
+	return &Settings{
+		TenantID:            TenantID,
+		URL:                 URL,
+		Container:           Container,
+		AccountName:         AccountName,
+		AccountKeyFile:      AccountKeyFile,
+		AccountKey:          AccountKey,
+		VerificationKeyFile: VerificationKeyFile,
+		MassifHeight:        MassifHeight,
+	}
+}
+
+// Value gets the value of the given setting, empty if there is no flag of the setting
+func (s *Settings) Value(name string) string {
+	return s.values[name].value
+}
+
+// Set sets the flags of the given flag set from the settings, e.g. the flags of the demo's own settings,
+//
+//	naming the setting and its source if the value is invalid.
+func (s *Settings) Set(fs *flag.FlagSet) error {
+
+	var setErr error
+	fs.VisitAll(func(f *flag.Flag) {
+
+		setting, ok := s.values[f.Name]
+		if setErr != nil || !ok {
+			return
+		}
+
+		setErr = setSetting(fs, f.Name, setting.value, setting.source)
+	})
+
+	return setErr
+}
+
+// parse parses the settings of the merklelog from their values
+func (s *Settings) parse() error {
+
+	s.TenantID = s.Value("tenant")
+	s.URL = s.Value("url")
+	s.Container = s.Value("container")
+	s.AccountName = s.Value("account-name")
+	s.AccountKeyFile = s.Value("account-key-file")
+	s.VerificationKeyFile = s.Value("verification-key")
+
+	massifHeight, ok := s.values["massif-height"]
+	if !ok {
+		return nil
+	}
+
+	height, err := strconv.ParseUint(massifHeight.value, 10, 0)
+	if err != nil {
+		return fmt.Errorf("%w: massif-height from %s: %v", ErrInvalidSetting, massifHeight.source, err)
+	}
+
+	s.MassifHeight = uint(height)
+
+	return nil
+}
+
+// configSetting is the value of a setting of the config file, and where in the config file it is from
+type configSetting struct {
+	value  string
+	source string
+}
+
+// settings gets the settings of the config file, and of the given profile, if any, keyed by flag name
+func (c *Config) settings(profile string) (map[string]configSetting, error) {
+
+	settings := map[string]configSetting{}
+
+	err := c.addSettings(settings, c.values, c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == "" {
+		return settings, nil
+	}
+
+	profileValues, ok := c.profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, expected one of %v", ErrUnknownProfile, profile, c.Profiles())
+	}
+
+	err = c.addSettings(settings, profileValues, fmt.Sprintf("profile %s of %s", profile, c.path))
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// addSettings adds the shared settings, then those of the demo's own section, of the given values of the config file
+func (c *Config) addSettings(settings map[string]configSetting, values map[string]any, source string) error {
+
+	for name, value := range values {
+
+		// the profile may be selected by the config file, but only at its top level
+		if slices.Contains(demos, name) || (name == profileFlag && source == c.path) {
+			continue
+		}
+
+		// a setting of only one demo is in the section of that demo, so the other demos do not reject it
+		if !slices.Contains(sharedSettings, name) && name != accountKeyName {
+			return fmt.Errorf("%w: %s in %s, only the settings shared by all the demos are at the top level, the settings of one demo are in its section", ErrUnknownSetting, name, source)
+		}
+
+		err := c.addSetting(settings, name, value, source)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the settings of the demo's own section take precedence over the shared settings
+	section, ok := values[c.demo]
+	if !ok {
+		return nil
+	}
+
+	sectionValues, ok := section.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: %s in %s, expected a section of settings", ErrInvalidSetting, c.demo, source)
+	}
+
+	for name, value := range sectionValues {
+
+		err := c.addSetting(settings, name, value, c.demo+" section of "+source)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addSetting adds the value of the config file to the settings, if there is a flag of the setting
+func (c *Config) addSetting(settings map[string]configSetting, name string, value any, source string) error {
+
+	if name == accountKeyName {
+		return fmt.Errorf("%w: %s in %s, the storage account key is only read from %s or the account-key-file", ErrUnknownSetting, name, source, configEnvName(accountKeyName))
+	}
+
+	if name == configFlag || name == profileFlag || c.fs.Lookup(name) == nil {
+		return fmt.Errorf("%w: %s in %s", ErrUnknownSetting, name, source)
+	}
+
+	settings[name] = configSetting{value: settingValue(value), source: source}
+
+	return nil
+}
+
+// selected gets the value of the given flag, set on the command line, or by its environment variable,
+//
+//	or at the top level of the config file, if any.
+func (c *Config) selected(name string) string {
+
+	if c.commandLine[name] {
+		return c.fs.Lookup(name).Value.String()
+	}
+
+	if value, ok := os.LookupEnv(configEnvName(name)); ok {
+		return value
+	}
+
+	if value, ok := c.values[name]; ok {
+		return settingValue(value)
+	}
+
+	return ""
+}
+
+// settingValue formats a value of the config file as the flag value, lists are comma separated
+func settingValue(value any) string {
+
+	list, ok := value.([]any)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		items = append(items, fmt.Sprint(item))
+	}
+
+	return strings.Join(items, ",")
+}
+
+// SplitList splits the value of a list setting, comma separated, dropping empty items
+func SplitList(value string) []string {
+
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// setSetting sets the flag of the given setting, naming the setting and its source if the value is invalid
+func setSetting(fs *flag.FlagSet, name string, value string, source string) error {
+
+	err := fs.Set(name, value)
+	if err != nil {
+		return fmt.Errorf("%w: %s from %s: %v", ErrInvalidSetting, name, source, err)
+	}
+
+	return nil
+}
+
+// configEnvName gets the environment variable of the given setting, e.g. DATATRAILS_LOG_LEVEL for log-level
+func configEnvName(name string) string {
+	return configEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// loadAccountKey reads the storage account key from its environment variable, or else the account key file, if any
+func (s *Settings) loadAccountKey() error {
+
+	if value, ok := os.LookupEnv(configEnvName(accountKeyName)); ok {
+		s.AccountKey = value
+		return nil
+	}
+
+	s.AccountKey = ""
+	if s.AccountKeyFile == "" {
+		return nil
+	}
+
+	accountKey, err := os.ReadFile(s.AccountKeyFile)
+	if err != nil {
+		return fmt.Errorf("%w: account-key-file: %v", ErrInvalidSetting, err)
+	}
+
+	s.AccountKey = strings.TrimSpace(string(accountKey))
+
+	return nil
+}
+
+// validate validates the settings of the merklelog, naming the offending setting
+func (s *Settings) validate() error {
+
+	if !strings.HasPrefix(s.TenantID, tenantPrefix) || len(s.TenantID) == len(tenantPrefix) {
+		return fmt.Errorf("%w: tenant %q, expected %s<uuid>", ErrInvalidSetting, s.TenantID, tenantPrefix)
+	}
+
+	if !strings.HasPrefix(s.URL, "https://") && !strings.HasPrefix(s.URL, "http://") {
+		return fmt.Errorf("%w: url %q, expected an http or https url", ErrInvalidSetting, s.URL)
+	}
+
+	if s.Container == "" {
+		return fmt.Errorf("%w: container is empty", ErrInvalidSetting)
+	}
+
+	if s.AccountKey != "" && s.AccountName == "" {
+		return fmt.Errorf("%w: account-name is empty, it is required with the storage account key", ErrInvalidSetting)
+	}
+
+	if s.MassifHeight > maxMassifHeight {
+		return fmt.Errorf("%w: massif-height %d, expected 1 to %d, or 0 to discover the massif height", ErrInvalidSetting, s.MassifHeight, maxMassifHeight)
+	}
+
+	_, err := os.Stat(s.VerificationKeyFile)
+	if err != nil {
+		return fmt.Errorf("%w: verification-key: %v", ErrInvalidSetting, err)
+	}
+
+	return nil
+}
+
+// NewBlobReader creates the reader of the blob storage of the merklelog of the shared settings,
+//
+//	authenticated with the storage account key if there is one.
+func NewBlobReader() (azblob.Reader, error) {
+	return Current().NewBlobReader()
+}
+
+// NewBlobReader creates the reader of the blob storage of the merklelog of the settings,
+//
+//	authenticated with the storage account key if there is one.
+func (s *Settings) NewBlobReader() (azblob.Reader, error) {
+
+	var reader *azblob.Storer
+	var err error
+
+	if s.AccountKey == "" {
+		reader, err = azblob.NewReaderNoAuth(s.URL, azblob.WithContainer(s.Container))
+	} else {
+		reader, err = azblob.NewReader(s.URL, azblob.WithContainer(s.Container), azblob.WithAccountName(s.AccountName), azblob.WithAccountKey(s.AccountKey))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
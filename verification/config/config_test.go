@@ -0,0 +1,465 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the tests in a temporary directory with a verification key file, the default verification-key
+func TestMain(m *testing.M) {
+
+	dir, err := os.MkdirTemp("", "config")
+	if err != nil {
+		panic(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, DefaultVerificationKeyFile), nil, 0o644)
+	if err == nil {
+		err = os.Chdir(dir)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// testConfigFlags registers the shared settings on a new flag set, restoring the shared settings after the test
+func testConfigFlags(t *testing.T) *flag.FlagSet {
+
+	tenantID, url, container := TenantID, URL, Container
+	accountName, accountKeyFile, accountKey, verificationKeyFile := AccountName, AccountKeyFile, AccountKey, VerificationKeyFile
+	logLevel, logFormat, traceExporter, massifHeight := LogLevel, LogFormat, TraceExporter, MassifHeight
+	t.Cleanup(func() {
+		TenantID, URL, Container = tenantID, url, container
+		AccountName, AccountKeyFile, AccountKey, VerificationKeyFile = accountName, accountKeyFile, accountKey, verificationKeyFile
+		LogLevel, LogFormat, TraceExporter, MassifHeight = logLevel, logFormat, traceExporter, massifHeight
+	})
+
+	fs := flag.NewFlagSet("consistency", flag.ContinueOnError)
+	Flags(fs)
+
+	return fs
+}
+
+// writeConfig writes the given YAML config file to a temporary directory
+func writeConfig(t *testing.T, configYaml string) string {
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(configYaml), 0o644))
+
+	return configPath
+}
+
+// TestLoadConfig_Precedence tests flags take precedence over environment variables,
+//
+//	which take precedence over the config file, which takes precedence over the defaults.
+func TestLoadConfig_Precedence(t *testing.T) {
+
+	configPath := writeConfig(t, `
+tenant: tenant/from-file
+container: file-container
+log-level: warn
+`)
+
+	t.Setenv("DATATRAILS_CONTAINER", "env-container")
+	t.Setenv("DATATRAILS_LOG_LEVEL", "error")
+
+	fs := testConfigFlags(t)
+	require.NoError(t, fs.Parse([]string{"-config", configPath, "-log-level", "debug"}))
+
+	_, err := Load(fs, "consistency")
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", LogLevel)
+	assert.Equal(t, "env-container", Container)
+	assert.Equal(t, "tenant/from-file", TenantID)
+	assert.Equal(t, DefaultURL, URL)
+}
+
+// TestLoadConfig_Sections tests the demo's own section takes precedence over the shared settings,
+//
+//	and the sections of the other demos are ignored.
+func TestLoadConfig_Sections(t *testing.T) {
+
+	configPath := writeConfig(t, `
+log-level: warn
+container: shared-container
+consistency:
+  log-level: debug
+inclusion:
+  events-url: https://app.datatrails.ai/archivist/v2
+`)
+
+	fs := testConfigFlags(t)
+	require.NoError(t, fs.Parse([]string{"-config", configPath}))
+
+	_, err := Load(fs, "consistency")
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", LogLevel)
+	assert.Equal(t, "shared-container", Container)
+
+	t.Run("unknown setting in own section", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		require.NoError(t, fs.Parse([]string{"-config", writeConfig(t, "consistency:\n  events-url: https://app.datatrails.ai\n")}))
+
+		_, err := Load(fs, "consistency")
+		assert.ErrorIs(t, err, ErrUnknownSetting)
+		assert.ErrorContains(t, err, "events-url in consistency section")
+	})
+}
+
+// TestLoadConfig_TopLevel tests a setting of only one demo is rejected at the top level of the config file,
+//
+//	and of a profile, even by that demo, so the config file is read the same way by every demo.
+func TestLoadConfig_TopLevel(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		configYaml string
+	}{
+		{
+			name:       "config file",
+			configYaml: "interval: 5m\n",
+		},
+		{
+			name:       "profile",
+			configYaml: "profile: public\nprofiles:\n  public:\n    interval: 5m\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			fs := testConfigFlags(t)
+			fs.Duration("interval", 0, "")
+			require.NoError(t, fs.Parse([]string{"-config", writeConfig(t, test.configYaml)}))
+
+			_, err := Load(fs, "inclusion")
+			assert.ErrorIs(t, err, ErrUnknownSetting)
+			assert.ErrorContains(t, err, "interval")
+			assert.ErrorContains(t, err, "in its section")
+		})
+	}
+
+	t.Run("in the section of the demo", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		interval := fs.Duration("interval", 0, "")
+		require.NoError(t, fs.Parse([]string{"-config", writeConfig(t, "inclusion:\n  interval: 5m\n")}))
+
+		_, err := Load(fs, "inclusion")
+		require.NoError(t, err)
+
+		assert.Equal(t, 5*time.Minute, *interval)
+	})
+}
+
+// TestLoadConfig_ConfigEnv tests the config file is taken from DATATRAILS_CONFIG if not given as a flag
+func TestLoadConfig_ConfigEnv(t *testing.T) {
+
+	t.Setenv("DATATRAILS_CONFIG", writeConfig(t, "url: http://localhost:10000/devstoreaccount1\n"))
+
+	fs := testConfigFlags(t)
+	require.NoError(t, fs.Parse(nil))
+
+	_, err := Load(fs, "consistency")
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:10000/devstoreaccount1", URL)
+}
+
+// TestLoadConfig_Invalid tests invalid settings are rejected, naming the offending setting
+func TestLoadConfig_Invalid(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		configYaml string
+		env        map[string]string
+		expected   error
+		setting    string
+	}{
+		{
+			name:       "unknown setting in config file",
+			configYaml: "tennant: tenant/typo\n",
+			expected:   ErrUnknownSetting,
+			setting:    "tennant",
+		},
+		{
+			name:     "tenant without prefix",
+			env:      map[string]string{"DATATRAILS_TENANT": "6ea5cd00-c711-3649-6914-7b125928bbb4"},
+			expected: ErrInvalidSetting,
+			setting:  "tenant",
+		},
+		{
+			name:       "url without scheme",
+			configYaml: "url: app.datatrails.ai/verifiabledata\n",
+			expected:   ErrInvalidSetting,
+			setting:    "url",
+		},
+		{
+			name:     "account key without account name",
+			env:      map[string]string{"DATATRAILS_ACCOUNT_KEY": "a2V5"},
+			expected: ErrInvalidSetting,
+			setting:  "account-name",
+		},
+		{
+			name:       "massif height too high to index",
+			configYaml: "massif-height: 65\n",
+			expected:   ErrInvalidSetting,
+			setting:    "massif-height",
+		},
+		{
+			name:     "missing verification key",
+			env:      map[string]string{"DATATRAILS_VERIFICATION_KEY": "missing.pem"},
+			expected: ErrInvalidSetting,
+			setting:  "verification-key",
+		},
+		{
+			name:       "unknown profile",
+			configYaml: "profiles:\n  public:\n    container: merklelogs\n",
+			env:        map[string]string{"DATATRAILS_PROFILE": "private"},
+			expected:   ErrUnknownProfile,
+			setting:    "private",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			args := []string{}
+			if test.configYaml != "" {
+				args = append(args, "-config", writeConfig(t, test.configYaml))
+			}
+
+			fs := testConfigFlags(t)
+			require.NoError(t, fs.Parse(args))
+
+			_, err := Load(fs, "consistency")
+			assert.ErrorIs(t, err, test.expected)
+			assert.ErrorContains(t, err, test.setting)
+		})
+	}
+}
+
+// TestLoadConfig_InvalidValue tests a value of the wrong type names the setting and where it came from
+func TestLoadConfig_InvalidValue(t *testing.T) {
+
+	t.Setenv("DATATRAILS_MONITOR_INTERVAL", "often")
+
+	fs := testConfigFlags(t)
+	fs.Duration("monitor-interval", time.Minute, "")
+	require.NoError(t, fs.Parse(nil))
+
+	_, err := Load(fs, "consistency")
+	assert.ErrorIs(t, err, ErrInvalidSetting)
+	assert.ErrorContains(t, err, "monitor-interval from DATATRAILS_MONITOR_INTERVAL")
+}
+
+// TestLoadConfig_AccountKey tests the storage account key is only read from its environment variable,
+//
+//	or else the account key file, never from a flag or the config file.
+func TestLoadConfig_AccountKey(t *testing.T) {
+
+	accountKeyFile := filepath.Join(t.TempDir(), "account-key")
+	require.NoError(t, os.WriteFile(accountKeyFile, []byte("a2V5\n"), 0o600))
+
+	t.Run("from the account key file", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		require.NoError(t, fs.Parse([]string{"-account-name", "privatea", "-account-key-file", accountKeyFile}))
+
+		_, err := Load(fs, "consistency")
+		require.NoError(t, err)
+
+		assert.Equal(t, "a2V5", AccountKey)
+	})
+
+	t.Run("environment variable takes precedence", func(t *testing.T) {
+
+		t.Setenv("DATATRAILS_ACCOUNT_KEY", "ZW52")
+
+		fs := testConfigFlags(t)
+		require.NoError(t, fs.Parse([]string{"-account-name", "privatea", "-account-key-file", accountKeyFile}))
+
+		_, err := Load(fs, "consistency")
+		require.NoError(t, err)
+
+		assert.Equal(t, "ZW52", AccountKey)
+	})
+
+	t.Run("not a flag", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		fs.SetOutput(io.Discard)
+		assert.Error(t, fs.Parse([]string{"-account-key", "a2V5"}))
+	})
+
+	t.Run("not in the config file", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		require.NoError(t, fs.Parse([]string{"-config", writeConfig(t, "profiles:\n  private-a:\n    account-key: a2V5\n")}))
+
+		configFile, err := Load(fs, "consistency")
+		require.NoError(t, err)
+
+		err = configFile.Load("private-a")
+		assert.ErrorIs(t, err, ErrUnknownSetting)
+		assert.ErrorContains(t, err, "DATATRAILS_ACCOUNT_KEY")
+	})
+
+	t.Run("missing account key file", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		require.NoError(t, fs.Parse([]string{"-account-key-file", filepath.Join(t.TempDir(), "missing")}))
+
+		_, err := Load(fs, "consistency")
+		assert.ErrorIs(t, err, ErrInvalidSetting)
+		assert.ErrorContains(t, err, "account-key-file")
+	})
+}
+
+// TestConfig_Profiles tests each profile takes precedence over the shared settings,
+//
+//	and loading another profile replaces all the settings of the previous profile.
+func TestConfig_Profiles(t *testing.T) {
+
+	configPath := writeConfig(t, `
+log-level: warn
+profile: public
+profiles:
+  public: {}
+  private-a:
+    tenant: tenant/private-a
+    url: https://privatea.blob.core.windows.net
+    account-name: privatea
+    consistency:
+      log-level: debug
+  private-b:
+    tenant: tenant/private-b
+    container: private-b-merklelogs
+`)
+
+	fs := testConfigFlags(t)
+	require.NoError(t, fs.Parse([]string{"-config", configPath}))
+
+	config, err := Load(fs, "consistency")
+	require.NoError(t, err)
+
+	assert.Equal(t, "public", config.Profile())
+	assert.Equal(t, []string{"private-a", "private-b", "public"}, config.Profiles())
+	assert.Equal(t, DefaultTenantID, TenantID)
+
+	require.NoError(t, config.Load("private-a"))
+
+	assert.Equal(t, "tenant/private-a", TenantID)
+	assert.Equal(t, "https://privatea.blob.core.windows.net", URL)
+	assert.Equal(t, "privatea", AccountName)
+	assert.Equal(t, "debug", LogLevel)
+
+	require.NoError(t, config.Load("private-b"))
+
+	assert.Equal(t, "tenant/private-b", TenantID)
+	assert.Equal(t, "private-b-merklelogs", Container)
+	assert.Equal(t, DefaultURL, URL)
+	assert.Equal(t, "", AccountKey)
+	assert.Equal(t, "warn", LogLevel)
+
+	t.Run("profile flag takes precedence over the config file", func(t *testing.T) {
+
+		fs := testConfigFlags(t)
+		require.NoError(t, fs.Parse([]string{"-config", configPath, "-profile", "private-b"}))
+
+		config, err := Load(fs, "consistency")
+		require.NoError(t, err)
+
+		assert.Equal(t, "private-b", config.Profile())
+		assert.Equal(t, "tenant/private-b", TenantID)
+	})
+}
+
+// TestConfig_Settings tests the settings of each profile are got without setting the flags or the shared settings,
+//
+//	so the settings of several profiles may be used at once.
+func TestConfig_Settings(t *testing.T) {
+
+	configPath := writeConfig(t, `
+profiles:
+  private-a:
+    tenant: tenant/private-a
+    account-name: privatea
+    massif-height: 3
+    consistency:
+      new-state-index: 830
+  private-b:
+    tenant: tenant/private-b
+    massif-height: high
+`)
+
+	fs := testConfigFlags(t)
+	fs.Int64("new-state-index", -1, "")
+	require.NoError(t, fs.Parse([]string{"-config", configPath, "-container", "merklelogs-a"}))
+
+	config, err := Load(fs, "consistency")
+	require.NoError(t, err)
+
+	settings, err := config.Settings("private-a")
+	require.NoError(t, err)
+
+	assert.Equal(t, "tenant/private-a", settings.TenantID)
+	assert.Equal(t, "merklelogs-a", settings.Container)
+	assert.Equal(t, "privatea", settings.AccountName)
+	assert.Equal(t, uint(3), settings.MassifHeight)
+	assert.Equal(t, "830", settings.Value("new-state-index"))
+
+	assert.Equal(t, DefaultTenantID, TenantID)
+	assert.Equal(t, uint(0), MassifHeight)
+	assert.Equal(t, "-1", fs.Lookup("new-state-index").Value.String())
+
+	t.Run("set onto the flags of the profile", func(t *testing.T) {
+
+		profileFlags := flag.NewFlagSet("private-a", flag.ContinueOnError)
+		newStateIndex := profileFlags.Int64("new-state-index", -1, "")
+
+		require.NoError(t, settings.Set(profileFlags))
+		assert.Equal(t, int64(830), *newStateIndex)
+	})
+
+	t.Run("invalid massif height", func(t *testing.T) {
+
+		_, err := config.Settings("private-b")
+		assert.ErrorIs(t, err, ErrInvalidSetting)
+		assert.ErrorContains(t, err, "massif-height from profile private-b")
+	})
+}
+
+// TestSettingValue tests lists in the config file are comma separated, as the flags expect
+func TestSettingValue(t *testing.T) {
+
+	assert.Equal(t, "http://peer-a:8080,http://peer-b:8080", settingValue([]any{"http://peer-a:8080", "http://peer-b:8080"}))
+	assert.Equal(t, "14", settingValue(14))
+	assert.Equal(t, "true", settingValue(true))
+}
+
+// TestSplitList tests list settings are split on commas, dropping empty items
+func TestSplitList(t *testing.T) {
+
+	assert.Equal(t, []string{"http://peer-a:8080", "http://peer-b:8080"}, SplitList("http://peer-a:8080,,http://peer-b:8080"))
+	assert.Equal(t, []string{}, SplitList(""))
+}
@@ -0,0 +1,169 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+)
+
+/**
+ * Entries holds utilities for placing events, as returned by the datatrails events API,
+ *  on the merklelog.
+ */
+
+var (
+	ErrEventNotIncluded = errors.New("event is not included on the merklelog")
+)
+
+// EventEntry is the part of a datatrails event needed to place the event on the merklelog.
+type EventEntry struct {
+	Identity       string `json:"identity"`
+	AssetIdentity  string `json:"asset_identity"`
+	TenantIdentity string `json:"tenant_identity"`
+	MerklelogEntry struct {
+		Commit struct {
+			Index       uint64 `json:"index,string"`
+			Idtimestamp string `json:"idtimestamp"`
+		} `json:"commit"`
+		Confirm     merklelog.MerklelogConfirm      `json:"confirm"`
+		Unequivocal *merklelog.MerklelogUnequivocal `json:"unequivocal"`
+	} `json:"merklelog_entry"`
+
+	// EventJson is the json of the whole event, as returned by the datatrails events API
+	EventJson []byte `json:"-"`
+}
+
+// MMRIndex of the leaf the event is committed to on the merklelog
+func (e EventEntry) MMRIndex() uint64 {
+	return e.MerklelogEntry.Commit.Index
+}
+
+// NewEventEntry creates an event entry from the json of a single event
+func NewEventEntry(eventJson []byte) (EventEntry, error) {
+
+	entry := EventEntry{}
+
+	err := json.Unmarshal(eventJson, &entry)
+	if err != nil {
+		return EventEntry{}, err
+	}
+
+	entry.EventJson = eventJson
+
+	return entry, nil
+}
+
+// NewEventEntries creates event entries from the json of an event list,
+//
+//	keeping the order of the event list.
+func NewEventEntries(eventsJson []byte) ([]EventEntry, error) {
+
+	eventList := struct {
+		Events []json.RawMessage `json:"events"`
+	}{}
+
+	err := json.Unmarshal(eventsJson, &eventList)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]EventEntry, 0, len(eventList.Events))
+	for _, eventJson := range eventList.Events {
+
+		entry, err := NewEventEntry(eventJson)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// SortByMMRIndex sorts the event entries by their merklelog_entry.commit.index, lowest first.
+func SortByMMRIndex(entries []EventEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].MMRIndex() < entries[j].MMRIndex()
+	})
+}
+
+// OmittedLeaves returns the mmr indexes of the leaves on the merklelog, between the lowest
+//
+//	and the highest given mmr index, that are not one of the given mmr indexes.
+//
+// NOTE: the given mmr indexes are expected to be leaves.
+func OmittedLeaves(mmrIndices []uint64) []uint64 {
+
+	if len(mmrIndices) == 0 {
+		return nil
+	}
+
+	included := make(map[uint64]bool, len(mmrIndices))
+	first, last := mmrIndices[0], mmrIndices[0]
+	for _, mmrIndex := range mmrIndices {
+		included[mmrIndex] = true
+		first = min(first, mmrIndex)
+		last = max(last, mmrIndex)
+	}
+
+	omitted := []uint64{}
+
+	// the leaf index of a leaf is the number of leaves before it on the merklelog
+	for leafIndex := mmr.LeafCount(first + 1); ; leafIndex++ {
+
+		mmrIndex := mmr.TreeIndex(leafIndex)
+		if mmrIndex >= last {
+			break
+		}
+
+		if !included[mmrIndex] {
+			omitted = append(omitted, mmrIndex)
+		}
+	}
+
+	return omitted
+}
+
+// VerifyEventEntry verifies the given event is included on the merklelog, of the given massif height
+func VerifyEventEntry(ctx context.Context, reader azblob.Reader, massifHeight uint8, entry EventEntry) (bool, error) {
+
+	verifiableEvent, err := logverification.NewVerifiableEvent(entry.EventJson)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse event %s: %w", entry.Identity, err)
+	}
+
+	err = CheckEventTenant(entry.Identity, entry.TenantIdentity)
+	if err != nil {
+		return false, err
+	}
+
+	return merklelog.VerifyEventInclusion(ctx, reader, *verifiableEvent, config.TenantID, massifHeight)
+}
+
+// VerifyEventEntries verifies each of the given events is included on the merklelog, of the given massif height,
+//
+//	returning whether each event is included, by the position of the event in the list.
+func VerifyEventEntries(ctx context.Context, reader azblob.Reader, massifHeight uint8, entries []EventEntry) ([]bool, error) {
+
+	included := make([]bool, 0, len(entries))
+	for _, entry := range entries {
+
+		verified, err := VerifyEventEntry(ctx, reader, massifHeight, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		included = append(included, verified)
+	}
+
+	return included, nil
+}
@@ -0,0 +1,32 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOmittedLeaves tests the leaves between the first and last mmr index,
+//
+//	that are not one of the mmr indexes, are omitted.
+func TestOmittedLeaves(t *testing.T) {
+
+	// the leaves of the merklelog are at mmr indexes 0, 1, 3, 4, 7, 8, 10, 11, 15, 16 ...
+	tests := []struct {
+		name       string
+		mmrIndices []uint64
+		expected   []uint64
+	}{
+		{name: "empty", mmrIndices: nil, expected: nil},
+		{name: "single", mmrIndices: []uint64{7}, expected: []uint64{}},
+		{name: "complete", mmrIndices: []uint64{3, 4, 7, 8}, expected: []uint64{}},
+		{name: "omitted", mmrIndices: []uint64{1, 8, 16}, expected: []uint64{3, 4, 7, 10, 11, 15}},
+		{name: "unsorted", mmrIndices: []uint64{11, 0, 4}, expected: []uint64{1, 3, 7, 8, 10}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, OmittedLeaves(test.mmrIndices))
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+/**
+ * Events API holds utilities for getting events from the datatrails events API,
+ *  so they can be verified against the merklelog by their identity.
+ */
+
+const (
+	// DefaultURL is the base url of the datatrails events API
+	DefaultURL = "https://app.datatrails.ai/archivist/v2"
+
+	// BearerTokenEnv is the environment variable holding the bearer token used
+	//  to get events of non public assets.
+	BearerTokenEnv = "DATATRAILS_BEARER_TOKEN"
+
+	// PageSize is the number of events requested per page when listing events
+	PageSize = 100
+
+	// PublicWildcardAssetIdentity lists the events of all public assets
+	PublicWildcardAssetIdentity = "publicassets/-"
+
+	// WildcardAssetIdentity lists the events of all assets of the tenant
+	WildcardAssetIdentity = "assets/-"
+)
+
+var (
+	eventIdentityRegex = regexp.MustCompile(`^(public)?assets/[0-9a-f-]{36}/events/[0-9a-f-]{36}$`)
+	assetIdentityRegex = regexp.MustCompile(`^(public)?assets/[0-9a-f-]{36}$`)
+
+	ErrInvalidEventIdentity = errors.New("invalid event identity, expected (public)assets/<uuid>/events/<uuid>")
+	ErrInvalidAssetIdentity = errors.New("invalid asset identity, expected (public)assets/<uuid>")
+	ErrNoBearerToken        = errors.New("a bearer token is required for non public assets")
+)
+
+// EventsAPI gets events from the datatrails events API.
+type EventsAPI struct {
+	baseURL     string
+	bearerToken string
+	client      *http.Client
+}
+
+// EventsAPIOption is an optional configuration for the EventsAPI.
+type EventsAPIOption func(*EventsAPI)
+
+// WithBearerToken sets the bearer token used to get events of non public assets.
+func WithBearerToken(bearerToken string) EventsAPIOption {
+	return func(api *EventsAPI) {
+		api.bearerToken = bearerToken
+	}
+}
+
+// WithHTTPClient sets the http client used to make requests to the events API.
+func WithHTTPClient(client *http.Client) EventsAPIOption {
+	return func(api *EventsAPI) {
+		api.client = client
+	}
+}
+
+// NewEventsAPI creates a new events API client for the given base url,
+//
+//	e.g. https://app.datatrails.ai/archivist/v2
+func NewEventsAPI(baseURL string, opts ...EventsAPIOption) *EventsAPI {
+
+	api := &EventsAPI{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(api)
+	}
+
+	return api
+}
+
+// Event gets the json of the event with the given identity,
+//
+//	in the format returned by the datatrails events API.
+func (api *EventsAPI) Event(ctx context.Context, eventIdentity string) ([]byte, error) {
+
+	if !eventIdentityRegex.MatchString(eventIdentity) {
+		return nil, ErrInvalidEventIdentity
+	}
+
+	return api.get(ctx, eventIdentity, nil)
+}
+
+// AssetEvents gets the json of every event of the asset with the given identity,
+//
+//	gathered across all pages into a single event list in the format returned
+//	by the datatrails events API.
+func (api *EventsAPI) AssetEvents(ctx context.Context, assetIdentity string) ([]byte, error) {
+
+	if !assetIdentityRegex.MatchString(assetIdentity) {
+		return nil, ErrInvalidAssetIdentity
+	}
+
+	eventList := struct {
+		Events []json.RawMessage `json:"events"`
+	}{
+		Events: []json.RawMessage{},
+	}
+
+	err := api.ListEvents(ctx, assetIdentity, func(page []json.RawMessage) (bool, error) {
+		eventList.Events = append(eventList.Events, page...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(eventList)
+}
+
+// ListEvents pages through the events of the asset with the given identity, or the events of all
+//
+//	assets for the wildcard asset identities publicassets/- and assets/-.
+//
+// onPage is called with the json of the events on each page, in the order returned by the
+// datatrails events API, until there are no more pages or onPage returns false.
+func (api *EventsAPI) ListEvents(ctx context.Context, assetIdentity string, onPage func(page []json.RawMessage) (bool, error)) error {
+
+	if !assetIdentityRegex.MatchString(assetIdentity) &&
+		assetIdentity != PublicWildcardAssetIdentity && assetIdentity != WildcardAssetIdentity {
+		return ErrInvalidAssetIdentity
+	}
+
+	pageToken := ""
+	for {
+		query := map[string]string{
+			"page_size": fmt.Sprintf("%d", PageSize),
+		}
+		if pageToken != "" {
+			query["page_token"] = pageToken
+		}
+
+		pageJson, err := api.get(ctx, assetIdentity+"/events", query)
+		if err != nil {
+			return err
+		}
+
+		page := struct {
+			Events        []json.RawMessage `json:"events"`
+			NextPageToken string            `json:"next_page_token"`
+		}{}
+
+		err = json.Unmarshal(pageJson, &page)
+		if err != nil {
+			return err
+		}
+
+		more, err := onPage(page.Events)
+		if err != nil {
+			return err
+		}
+
+		if !more || page.NextPageToken == "" {
+			return nil
+		}
+
+		pageToken = page.NextPageToken
+	}
+}
+
+// get the json response of the events API for the given resource path.
+func (api *EventsAPI) get(ctx context.Context, resourcePath string, query map[string]string) ([]byte, error) {
+
+	// only public assets can be read without authentication
+	public := strings.HasPrefix(resourcePath, "publicassets/")
+	if !public && api.bearerToken == "" {
+		return nil, ErrNoBearerToken
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s", api.baseURL, resourcePath), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	requestQuery := request.URL.Query()
+	for key, value := range query {
+		requestQuery.Set(key, value)
+	}
+	request.URL.RawQuery = requestQuery.Encode()
+
+	request.Header.Set("Accept", "application/json")
+	if !public {
+		request.Header.Set("Authorization", "Bearer "+api.bearerToken)
+	}
+
+	response, err := api.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("events API request for %s failed with status %d: %s", resourcePath, response.StatusCode, body)
+	}
+
+	return body, nil
+}
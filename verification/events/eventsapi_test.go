@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/events/eventstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAssetIdentity = "assets/6e3c2ee9-4a1d-4b5d-9df3-a4b0c1e2f3a4"
+)
+
+// TestEventsAPI_Event tests getting a public event by its identity
+func TestEventsAPI_Event(t *testing.T) {
+
+	event := eventstest.MockAssetEvent("publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6", "71d7ab65-359b-40d9-9bbd-102ec2092601")
+
+	eventsAPI := NewEventsAPI(eventstest.NewMockEventsAPI(t, PageSize, event))
+
+	eventJson, err := eventsAPI.Event(context.Background(), "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, event, string(eventJson))
+
+	_, err = eventsAPI.Event(context.Background(), "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6")
+	assert.ErrorIs(t, err, ErrInvalidEventIdentity)
+}
+
+// TestEventsAPI_AssetEvents tests all the events of an asset are gathered across pages,
+//
+//	and that non public assets require the bearer token.
+func TestEventsAPI_AssetEvents(t *testing.T) {
+
+	eventsURL := eventstest.NewMockEventsAPI(t, 2,
+		eventstest.MockAssetEvent(testAssetIdentity, "00000000-0000-0000-0000-000000000001"),
+		eventstest.MockAssetEvent(testAssetIdentity, "00000000-0000-0000-0000-000000000002"),
+		eventstest.MockAssetEvent("assets/7f4d3ff0-5b2e-4c6e-aef4-b5c1d2e3f4a5", "00000000-0000-0000-0000-000000000003"),
+		eventstest.MockAssetEvent(testAssetIdentity, "00000000-0000-0000-0000-000000000004"),
+	)
+
+	_, err := NewEventsAPI(eventsURL).AssetEvents(context.Background(), testAssetIdentity)
+	assert.ErrorIs(t, err, ErrNoBearerToken)
+
+	eventsJson, err := NewEventsAPI(eventsURL, WithBearerToken(eventstest.MockBearerToken)).AssetEvents(context.Background(), testAssetIdentity)
+	require.NoError(t, err)
+
+	eventList := struct {
+		Events []struct {
+			Identity string `json:"identity"`
+		} `json:"events"`
+	}{}
+
+	err = json.Unmarshal(eventsJson, &eventList)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, len(eventList.Events))
+	assert.Equal(t, testAssetIdentity+"/events/00000000-0000-0000-0000-000000000001", eventList.Events[0].Identity)
+	assert.Equal(t, testAssetIdentity+"/events/00000000-0000-0000-0000-000000000002", eventList.Events[1].Identity)
+	assert.Equal(t, testAssetIdentity+"/events/00000000-0000-0000-0000-000000000004", eventList.Events[2].Identity)
+}
+
+// TestEventsAPI_ListEvents tests the wildcard asset identity pages through the events of every asset,
+//
+//	stopping early once a page is declined.
+func TestEventsAPI_ListEvents(t *testing.T) {
+
+	eventsAPI := NewEventsAPI(eventstest.NewMockEventsAPI(t, 1,
+		eventstest.MockAssetEvent(testAssetIdentity, "00000000-0000-0000-0000-000000000001"),
+		eventstest.MockAssetEvent("assets/7f4d3ff0-5b2e-4c6e-aef4-b5c1d2e3f4a5", "00000000-0000-0000-0000-000000000002"),
+		eventstest.MockAssetEvent(testAssetIdentity, "00000000-0000-0000-0000-000000000003"),
+	), WithBearerToken(eventstest.MockBearerToken))
+
+	pages := 0
+	err := eventsAPI.ListEvents(context.Background(), WildcardAssetIdentity, func(page []json.RawMessage) (bool, error) {
+		pages++
+		return pages < 2, nil
+	})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, pages)
+
+	err = eventsAPI.ListEvents(context.Background(), "assets/-/events", nil)
+	assert.ErrorIs(t, err, ErrInvalidAssetIdentity)
+}
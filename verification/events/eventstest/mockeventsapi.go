@@ -0,0 +1,115 @@
+package eventstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+/**
+ * Events test holds a local stand in for the datatrails events API, for the tests of the demos
+ *  that get their events from it.
+ */
+
+const (
+	// MockBearerToken is the only bearer token the mock events API accepts for non public assets
+	MockBearerToken = "mock-bearer-token"
+)
+
+// NewMockEventsAPI creates a local stand in for the datatrails events API,
+//
+//	serving the given events by identity, and listing them by asset identity,
+//	or wildcard asset identity, in pages of the given size.
+//
+// Returns the base url of the mock events API.
+func NewMockEventsAPI(t *testing.T, pageSize int, eventsJson ...string) string {
+
+	type mockEvent struct {
+		Identity      string `json:"identity"`
+		AssetIdentity string `json:"asset_identity"`
+	}
+
+	events := make([]mockEvent, len(eventsJson))
+	for i, eventJson := range eventsJson {
+		err := json.Unmarshal([]byte(eventJson), &events[i])
+		require.NoError(t, err)
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+
+		resourcePath := strings.TrimPrefix(r.URL.Path, "/archivist/v2/")
+
+		if !strings.HasPrefix(resourcePath, "publicassets/") && r.Header.Get("Authorization") != "Bearer "+MockBearerToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// get a single event
+		for i, event := range events {
+			if event.Identity == resourcePath {
+				fmt.Fprint(w, eventsJson[i])
+				return
+			}
+		}
+
+		// list the events of an asset
+		assetIdentity, found := strings.CutSuffix(resourcePath, "/events")
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// the wildcard asset identity lists the events of all assets
+		assetPrefix, wildcard := strings.CutSuffix(assetIdentity, "-")
+
+		assetEvents := []json.RawMessage{}
+		for i, event := range events {
+			if event.AssetIdentity == assetIdentity || (wildcard && strings.HasPrefix(event.AssetIdentity, assetPrefix)) {
+				assetEvents = append(assetEvents, json.RawMessage(eventsJson[i]))
+			}
+		}
+
+		start := 0
+		if pageToken := r.URL.Query().Get("page_token"); pageToken != "" {
+			var err error
+			start, err = strconv.Atoi(pageToken)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		end := min(start+pageSize, len(assetEvents))
+		nextPageToken := ""
+		if end < len(assetEvents) {
+			nextPageToken = strconv.Itoa(end)
+		}
+
+		page, err := json.Marshal(map[string]any{
+			"events":          assetEvents[start:end],
+			"next_page_token": nextPageToken,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, "%s", page)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+
+	return server.URL + "/archivist/v2"
+}
+
+// MockAssetEvent creates a minimal event json for the given asset and event uuids
+func MockAssetEvent(assetIdentity string, eventUUID string) string {
+	return fmt.Sprintf(`{"identity": "%s/events/%s", "asset_identity": "%s"}`, assetIdentity, eventUUID, assetIdentity)
+}
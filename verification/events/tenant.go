@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+)
+
+/**
+ * Tenant checks events are on the merklelog of the configured tenant, before they are verified against it.
+ *
+ * The events of public assets are all on the merklelog of the public tenant, the events of any other
+ *  asset are on the merklelog of their own tenant, which must be configured with -tenant to verify them.
+ */
+
+const (
+	// privateAssetPrefix prefixes the identities of the assets, and their events, that are not public
+	privateAssetPrefix = "assets/"
+)
+
+var (
+	ErrEventTenant = errors.New("event of a non public asset is on the merklelog of its own tenant, which is not the configured tenant")
+)
+
+// eventTenant is the part of a datatrails event needed to find the merklelog it is on
+type eventTenant struct {
+	Identity       string `json:"identity"`
+	TenantIdentity string `json:"tenant_identity"`
+}
+
+// CheckEventTenant checks the event with the given identity, and tenant identity, is on the merklelog
+//
+//	of the configured tenant.
+func CheckEventTenant(identity string, tenantIdentity string) error {
+
+	if !strings.HasPrefix(identity, privateAssetPrefix) {
+		return nil
+	}
+
+	if tenantIdentity != config.TenantID {
+		return fmt.Errorf("%w: event %s of tenant %q, configured tenant %q", ErrEventTenant, identity, tenantIdentity, config.TenantID)
+	}
+
+	return nil
+}
+
+// CheckEventTenants checks each of the given events, in the json of a single event or
+//
+//	of a list of events, is on the merklelog of the configured tenant.
+func CheckEventTenants(eventsJson []byte) error {
+
+	eventList := struct {
+		Events []eventTenant `json:"events"`
+	}{}
+
+	err := json.Unmarshal(eventsJson, &eventList)
+	if err != nil {
+		return err
+	}
+
+	if eventList.Events == nil {
+
+		event := eventTenant{}
+
+		err = json.Unmarshal(eventsJson, &event)
+		if err != nil {
+			return err
+		}
+
+		eventList.Events = []eventTenant{event}
+	}
+
+	for _, event := range eventList.Events {
+
+		err = CheckEventTenant(event.Identity, event.TenantIdentity)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
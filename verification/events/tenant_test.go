@@ -0,0 +1,62 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPrivateEventIdentity = "assets/6e3c2ee9-4a1d-4b5d-9df3-a4b0c1e2f3a4/events/00000000-0000-0000-0000-000000000001"
+	testPrivateTenantID      = "tenant/00000000-0000-0000-0000-000000000001"
+)
+
+// TestCheckEventTenant tests the events of non public assets are only verified
+//
+//	against the merklelog of their own tenant.
+func TestCheckEventTenant(t *testing.T) {
+
+	// the events of public assets are on the merklelog of the public tenant, whatever tenant recorded them
+	err := CheckEventTenant("publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601", "tenant/f023005c-000f-4a57-b2fe-eef425f243ad")
+	assert.Equal(t, nil, err)
+
+	err = CheckEventTenant(testPrivateEventIdentity, testPrivateTenantID)
+	assert.ErrorIs(t, err, ErrEventTenant)
+
+	defer func(tenantID string) { config.TenantID = tenantID }(config.TenantID)
+	config.TenantID = testPrivateTenantID
+
+	err = CheckEventTenant(testPrivateEventIdentity, testPrivateTenantID)
+	assert.Equal(t, nil, err)
+}
+
+// TestCheckEventTenants tests the tenant of each event is checked, in the json of a single event
+//
+//	or of a list of events.
+func TestCheckEventTenants(t *testing.T) {
+
+	publicEvent := `{"identity": "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601", "tenant_identity": "tenant/f023005c-000f-4a57-b2fe-eef425f243ad"}`
+	privateEvent := `{"identity": "` + testPrivateEventIdentity + `", "tenant_identity": "` + testPrivateTenantID + `"}`
+
+	t.Run("public event", func(t *testing.T) {
+		err := CheckEventTenants([]byte(publicEvent))
+		assert.Equal(t, nil, err)
+	})
+
+	t.Run("private event, public tenant", func(t *testing.T) {
+		err := CheckEventTenants([]byte(privateEvent))
+		assert.ErrorIs(t, err, ErrEventTenant)
+
+		err = CheckEventTenants([]byte(`{"events": [` + publicEvent + `,` + privateEvent + `]}`))
+		assert.ErrorIs(t, err, ErrEventTenant)
+	})
+
+	t.Run("private event, own tenant", func(t *testing.T) {
+		defer func(tenantID string) { config.TenantID = tenantID }(config.TenantID)
+		config.TenantID = testPrivateTenantID
+
+		err := CheckEventTenants([]byte(`{"events": [` + privateEvent + `]}`))
+		assert.Equal(t, nil, err)
+	})
+}
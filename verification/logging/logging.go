@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+/**
+ * Logging of the demos, structured with slog so verification runs can be shipped to log aggregation,
+ *  and correlated with incidents.
+ *
+ * The logs are written as text or json, at the configured level. Fetches of blobs are logged at debug level.
+ */
+
+const (
+	// FormatText and FormatJSON are the formats of the logs
+	FormatText = "text"
+	FormatJSON = "json"
+
+	// keys of the log fields, consistent across the demos for correlation
+	KeyTenant        = "tenant"
+	KeyProfile       = "profile"
+	KeyMassifIndex   = "massif_index"
+	KeyMMRIndex      = "mmr_index"
+	KeyMMRIndices    = "mmr_indices"
+	KeyMMRSize       = "mmr_size"
+	KeyEventIdentity = "event_identity"
+	KeyAssetIdentity = "asset_identity"
+	KeyBlobPath      = "blob_path"
+	KeyDuration      = "duration"
+	KeyError         = "error"
+)
+
+var (
+	ErrInvalidLevel  = errors.New("invalid log level, expected one of debug, info, warn or error")
+	ErrInvalidFormat = errors.New("invalid log format, expected one of text or json")
+)
+
+// New creates a logger writing to the given writer, at the given level, in the given format
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+
+	var logLevel slog.Level
+	err := logLevel.UnmarshalText([]byte(level))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLevel, level)
+	}
+
+	options := &slog.HandlerOptions{Level: logLevel}
+
+	switch format {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, format)
+	}
+}
+
+// Setup sets the default logger, writing to stdout, at the given level, in the given format
+func Setup(level string, format string) error {
+
+	logger, err := New(os.Stdout, level, format)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(logger)
+
+	return nil
+}
+
+// BlobFetch logs the fetch of a blob of the tenant's merklelog, started at the given time
+func BlobFetch(tenantID string, massifIndex uint64, blobPath string, start time.Time, err error) {
+
+	attrs := []any{
+		KeyTenant, tenantID,
+		KeyMassifIndex, massifIndex,
+		KeyBlobPath, blobPath,
+		KeyDuration, time.Since(start),
+	}
+
+	if err != nil {
+		slog.Debug("failed to fetch blob", append(attrs, KeyError, err)...)
+		return
+	}
+
+	slog.Debug("fetched blob", attrs...)
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew tests logs are written in json, with their fields, at or above the configured level
+func TestNew(t *testing.T) {
+
+	tenantID := "tenant/6ea5cd00-c711-3649-6914-7b125928bbb4"
+
+	var logs bytes.Buffer
+
+	logger, err := New(&logs, "warn", FormatJSON)
+	require.NoError(t, err)
+
+	logger.Info("not logged below the level")
+	logger.Warn("fetched blob", KeyTenant, tenantID, KeyMassifIndex, 3)
+
+	record := map[string]any{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "fetched blob", record["msg"])
+	assert.Equal(t, tenantID, record[KeyTenant])
+	assert.Equal(t, float64(3), record[KeyMassifIndex])
+}
+
+// TestNew_Invalid tests unknown levels and formats are rejected
+func TestNew_Invalid(t *testing.T) {
+
+	_, err := New(&bytes.Buffer{}, "verbose", FormatText)
+	assert.ErrorIs(t, err, ErrInvalidLevel)
+
+	_, err = New(&bytes.Buffer{}, "info", "xml")
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	t.Run("level is case insensitive", func(t *testing.T) {
+
+		_, err := New(&bytes.Buffer{}, "DEBUG", FormatText)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,40 @@
+package merklelog
+
+import (
+	"errors"
+	"net/http"
+)
+
+/**
+ * Blob not found tells a blob missing from the blob storage, e.g. the seal of a massif not sealed yet,
+ *  from any other failure to read it, e.g. the blob storage being unreachable or denying access.
+ *
+ * Only a missing blob is expected while verifying, so only a missing blob may be worked around.
+ */
+
+var (
+	// ErrBlobNotFound is returned by readers when a blob is not in the blob storage, e.g. a massif not sealed yet
+	ErrBlobNotFound = errors.New("blob not found")
+)
+
+// statusCoder is an error of a request to the blob storage, with the http status code of its response
+type statusCoder interface {
+	StatusCode() int
+}
+
+// IsBlobNotFound is true if the given error is from reading a blob that is not in the blob storage,
+//
+//	either wrapping ErrBlobNotFound, or the not found http status code of the blob storage.
+func IsBlobNotFound(err error) bool {
+
+	if errors.Is(err, ErrBlobNotFound) {
+		return true
+	}
+
+	var statusErr statusCoder
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode() == http.StatusNotFound
+	}
+
+	return false
+}
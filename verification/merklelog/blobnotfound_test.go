@@ -0,0 +1,47 @@
+package merklelog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStatusError is an error of the blob storage with an http status code
+type testStatusError struct {
+	statusCode int
+}
+
+// Error of the blob storage
+func (e *testStatusError) Error() string {
+	return fmt.Sprintf("blob storage responded %d", e.statusCode)
+}
+
+// StatusCode of the response of the blob storage
+func (e *testStatusError) StatusCode() int {
+	return e.statusCode
+}
+
+// TestIsBlobNotFound tests only a missing blob is not found, not any other failure to read it
+func TestIsBlobNotFound(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "no error", err: nil, expected: false},
+		{name: "blob not found", err: fmt.Errorf("%w: seal", ErrBlobNotFound), expected: true},
+		{name: "not found status", err: fmt.Errorf("failed to read seal: %w", &testStatusError{statusCode: http.StatusNotFound}), expected: true},
+		{name: "forbidden status", err: &testStatusError{statusCode: http.StatusForbidden}, expected: false},
+		{name: "other error", err: errors.New("connection refused"), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, IsBlobNotFound(test.err))
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package merklelog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+)
+
+/**
+ * Confirm verifies the merklelog_entry.confirm of events.
+ *
+ * Every event confirms the root of the merklelog at an mmr size that includes the event.
+ *  The root is recomputed at that mmr size from the massif data, and must match the
+ *  root the event claims.
+ */
+
+// ConfirmStatus is the result of verifying the merklelog_entry.confirm of an event
+type ConfirmStatus string
+
+const (
+	// ConfirmVerified the confirmed root matches the root recomputed at the confirmed mmr size
+	ConfirmVerified ConfirmStatus = "verified"
+
+	// ConfirmMissing the event has no confirmed mmr size or root
+	ConfirmMissing ConfirmStatus = "missing"
+
+	// ConfirmStale the confirmed mmr size does not include the leaf of the event,
+	//  so the confirmation predates the event.
+	ConfirmStale ConfirmStatus = "stale"
+
+	// ConfirmInconsistent the confirmed mmr size is not a valid mmr size, is beyond the end of the
+	//  merklelog, or the confirmed root does not match the root recomputed at the confirmed mmr size.
+	ConfirmInconsistent ConfirmStatus = "inconsistent"
+
+	// verificationConfirmation is the type of verification of the confirmation metrics
+	verificationConfirmation = "confirmation"
+)
+
+// EventConfirmation is the result of verifying the merklelog_entry.confirm of an event in a list
+type EventConfirmation struct {
+	Identity string
+	Status   ConfirmStatus
+
+	// SignedTreeHead is the result of verifying the merklelog_entry.confirm.signed_tree_head
+	SignedTreeHead TreeHeadStatus
+
+	// Unequivocal is the result of verifying the signed tree head of the merklelog_entry.unequivocal
+	Unequivocal TreeHeadStatus
+}
+
+// Failed is true if the confirmation of the event is missing, stale or inconsistent with the merklelog,
+//
+//	or a signed tree head of the event is invalid or inconsistent with the merklelog.
+func (ec EventConfirmation) Failed() bool {
+	return ec.Status != ConfirmVerified ||
+		ec.SignedTreeHead == TreeHeadInvalid || ec.SignedTreeHead == TreeHeadInconsistent ||
+		ec.Unequivocal == TreeHeadInvalid || ec.Unequivocal == TreeHeadInconsistent
+}
+
+// confirmedEvent is the part of a datatrails event needed to verify its merklelog_entry.confirm
+type confirmedEvent struct {
+	Identity       string `json:"identity"`
+	MerklelogEntry struct {
+		Commit struct {
+			Index uint64 `json:"index,string"`
+		} `json:"commit"`
+		Confirm     MerklelogConfirm      `json:"confirm"`
+		Unequivocal *MerklelogUnequivocal `json:"unequivocal"`
+	} `json:"merklelog_entry"`
+}
+
+// VerifyEventConfirmation verifies the merklelog_entry.confirm, and any signed tree heads, of a datatrails event
+func VerifyEventConfirmation(ctx context.Context, eventJson []byte) (EventConfirmation, error) {
+
+	confirmations, err := VerifyEventConfirmations(ctx, []byte(fmt.Sprintf(`{"events": [%s]}`, eventJson)))
+	if err != nil {
+		return EventConfirmation{}, err
+	}
+
+	return confirmations[0], nil
+}
+
+// VerifyEventConfirmations verifies the merklelog_entry.confirm, and any signed tree heads,
+//
+//	of every event in the given list of events, on the merklelog of the configured tenant.
+func VerifyEventConfirmations(ctx context.Context, eventsJson []byte) ([]EventConfirmation, error) {
+
+	eventList := struct {
+		Events []confirmedEvent `json:"events"`
+	}{}
+
+	err := json.Unmarshal(eventsJson, &eventList)
+	if err != nil {
+		return nil, err
+	}
+
+	// then create the merklelog reader
+	reader, err := NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	massifCache := NewMassifCache(ctx, reader, config.TenantID, uint8(config.MassifHeight))
+
+	massifHeight, err := massifCache.MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	verificationKey, err := VerificationKeyFromFile(config.VerificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	treeHeadVerifier, err := NewTreeHeadVerifier(ctx, reader, config.TenantID, massifHeight, verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmations := make([]EventConfirmation, 0, len(eventList.Events))
+	for _, event := range eventList.Events {
+
+		status, err := VerifyConfirmation(massifCache, event.MerklelogEntry.Commit.Index, event.MerklelogEntry.Confirm)
+		if err != nil {
+			return nil, err
+		}
+
+		signedTreeHead, unequivocal, err := VerifyTreeHeads(treeHeadVerifier, event.MerklelogEntry.Confirm, event.MerklelogEntry.Unequivocal)
+		if err != nil {
+			return nil, err
+		}
+
+		confirmation := EventConfirmation{
+			Identity:       event.Identity,
+			Status:         status,
+			SignedTreeHead: signedTreeHead,
+			Unequivocal:    unequivocal,
+		}
+
+		metrics.Verification(verificationConfirmation, !confirmation.Failed(), nil)
+		if status == ConfirmVerified {
+
+			// a verified confirmation has a valid mmr size
+			mmrSize, _ := strconv.ParseUint(event.MerklelogEntry.Confirm.MMRSize, 10, 64)
+			metrics.LatestVerified(mmrSize)
+		}
+
+		confirmations = append(confirmations, confirmation)
+	}
+
+	return confirmations, nil
+}
+
+// VerifyConfirmation verifies the given confirmation of the leaf at the given mmr index,
+//
+//	by recomputing the root of the merklelog at the confirmed mmr size from the massif data.
+func VerifyConfirmation(massifCache *MassifCache, mmrIndex uint64, confirm MerklelogConfirm) (ConfirmStatus, error) {
+
+	if confirm.MMRSize == "" || len(confirm.Root) == 0 {
+		return ConfirmMissing, nil
+	}
+
+	// only the size of a complete mmr, a series of perfect binary trees each strictly lower than the last, has peaks
+	mmrSize, err := strconv.ParseUint(confirm.MMRSize, 10, 64)
+	if err != nil || mmr.Peaks(mmrSize) == nil {
+		return ConfirmInconsistent, nil
+	}
+
+	if mmrSize <= mmrIndex {
+		return ConfirmStale, nil
+	}
+
+	// the massif containing the last node of the confirmed mmr has all the peaks of the confirmed mmr,
+	//  either in the massif itself, or in its peak stack.
+	massifContext, err := massifCache.Massif(mmrSize - 1)
+	if err != nil {
+
+		// there is no massif beyond the head massif, so the confirmed mmr size may be beyond the end of the merklelog
+		headMassif, headErr := massifCache.HeadMassif()
+		if headErr == nil && mmrSize > headMassif.RangeCount() {
+			return ConfirmInconsistent, nil
+		}
+
+		return "", err
+	}
+
+	if mmrSize > massifContext.RangeCount() {
+		return ConfirmInconsistent, nil
+	}
+
+	root, err := mmr.GetRoot(mmrSize, massifContext, sha256.New())
+	if err != nil {
+		return "", err
+	}
+
+	if !bytes.Equal(root, confirm.Root) {
+		return ConfirmInconsistent, nil
+	}
+
+	return ConfirmVerified, nil
+}
@@ -0,0 +1,102 @@
+package merklelog
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyConfirmation_MMRSize tests only the sizes of complete mmrs are valid confirmed mmr sizes
+func TestVerifyConfirmation_MMRSize(t *testing.T) {
+
+	root := []byte("SnBhDOt7lF/aTK48db1qk0/86dluDr+y")
+
+	// every valid mmr size is stale for a leaf beyond it, so no massif is read
+	valid := []uint64{}
+	for mmrSize := uint64(0); mmrSize <= 26; mmrSize++ {
+
+		status, err := VerifyConfirmation(nil, 1000, MerklelogConfirm{MMRSize: strconv.FormatUint(mmrSize, 10), Root: root})
+		require.NoError(t, err)
+
+		if status != ConfirmInconsistent {
+			valid = append(valid, mmrSize)
+		}
+	}
+
+	assert.Equal(t, []uint64{1, 3, 4, 7, 8, 10, 11, 15, 16, 18, 19, 22, 23, 25, 26}, valid)
+}
+
+// TestVerifyConfirmation_Unconfirmed tests missing, stale and invalid confirmations
+//
+//	are flagged without reading the merklelog.
+func TestVerifyConfirmation_Unconfirmed(t *testing.T) {
+
+	root := []byte("SnBhDOt7lF/aTK48db1qk0/86dluDr+y")
+
+	tests := []struct {
+		name     string
+		confirm  MerklelogConfirm
+		expected ConfirmStatus
+	}{
+		{name: "missing mmr size", confirm: MerklelogConfirm{Root: root}, expected: ConfirmMissing},
+		{name: "missing root", confirm: MerklelogConfirm{MMRSize: "512"}, expected: ConfirmMissing},
+		{name: "invalid mmr size", confirm: MerklelogConfirm{MMRSize: "510", Root: root}, expected: ConfirmInconsistent},
+		{name: "stale", confirm: MerklelogConfirm{MMRSize: "499", Root: root}, expected: ConfirmStale},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			status, err := VerifyConfirmation(nil, 511, test.confirm)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.expected, status)
+		})
+	}
+}
+
+// TestEventConfirmation_Failed tests every confirmation that is not verified fails,
+//
+//	as does any invalid or inconsistent signed tree head.
+func TestEventConfirmation_Failed(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		confirmation EventConfirmation
+		expected     bool
+	}{
+		{"verified", EventConfirmation{Status: ConfirmVerified, SignedTreeHead: TreeHeadVerified, Unequivocal: TreeHeadAbsent}, false},
+		{"missing", EventConfirmation{Status: ConfirmMissing, SignedTreeHead: TreeHeadAbsent, Unequivocal: TreeHeadAbsent}, true},
+		{"stale", EventConfirmation{Status: ConfirmStale, SignedTreeHead: TreeHeadAbsent, Unequivocal: TreeHeadAbsent}, true},
+		{"inconsistent", EventConfirmation{Status: ConfirmInconsistent, SignedTreeHead: TreeHeadAbsent, Unequivocal: TreeHeadAbsent}, true},
+		{"invalid signed tree head", EventConfirmation{Status: ConfirmVerified, SignedTreeHead: TreeHeadInvalid, Unequivocal: TreeHeadAbsent}, true},
+		{"inconsistent unequivocal", EventConfirmation{Status: ConfirmVerified, SignedTreeHead: TreeHeadVerified, Unequivocal: TreeHeadInconsistent}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.confirmation.Failed())
+		})
+	}
+}
+
+// TestVerifyConfirmation_BeyondLog tests a confirmed mmr size beyond the end of the merklelog
+//
+//	is inconsistent, rather than failing to read a massif that does not exist.
+func TestVerifyConfirmation_BeyondLog(t *testing.T) {
+
+	reader, err := NewReader(context.Background())
+	require.NoError(t, err)
+
+	massifCache := NewMassifCache(context.Background(), reader, config.TenantID, uint8(config.MassifHeight))
+
+	// a single perfect binary tree of 2^39 leaves, far beyond the end of the merklelog
+	status, err := VerifyConfirmation(massifCache, 511, MerklelogConfirm{MMRSize: "1099511627775", Root: []byte("SnBhDOt7lF/aTK48db1qk0/86dluDr+y")})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ConfirmInconsistent, status)
+}
@@ -0,0 +1,76 @@
+package merklelog
+
+import (
+	"context"
+	"crypto"
+	"os"
+
+	"github.com/datatrails/go-datatrails-common/cose"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * Existing log state is an existing log state based on the breast cancer diagnosing AI model sample
+ *  found here: https://app.datatrails.ai/archivist/publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/9a192afe-9253-44d7-8585-c48f237f2134
+ *
+ * The existing log state will take the root of the massif the event is found in, in this case the mmrIndex of 511 of the public tenant.
+ *
+ * Another signed log state, e.g. saved earlier for a private tenant, may be trusted instead with -trusted-seal.
+ */
+
+// TrustedSeal gets the signed log state trusted as the existing log state, read from the given trusted seal file,
+//
+//	or else the sample signed state of the public tenant if there is no trusted seal file.
+func TrustedSeal(trustedSealFile string) ([]byte, error) {
+
+	if trustedSealFile == "" {
+		return SampleSignedStateCbor, nil
+	}
+
+	return os.ReadFile(trustedSealFile)
+}
+
+// ExistingSignedState gets the existing signed state for the log at the massif where
+//
+//	the event for the breast cancer diagnosing AI model sample is found.
+//	  The event can be found here: https://app.datatrails.ai/archivist/publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/9a192afe-9253-44d7-8585-c48f237f2134
+//
+// Or the seal of the given trusted seal file, if there is one.
+//
+// Then verifies the existing signed state signature against using the known veriication key, of the given verification key file.
+func ExistingSignedState(ctx context.Context, trustedSealFile string, verificationKeyFile string) (*massifs.MMRState, error) {
+	trustedSeal, err := TrustedSeal(trustedSealFile)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationKey, err := VerificationKeyFromFile(verificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return VerifiedLogState(ctx, trustedSeal, verificationKey)
+}
+
+// VerifiedLogState verifies the given signed log state with the given verification key,
+//
+//	and unmarshals it into a golang data structure.
+func VerifiedLogState(ctx context.Context, signedStateCbor []byte, verificationKey crypto.PublicKey) (*massifs.MMRState, error) {
+
+	signedState, err := cose.NewCoseSign1MessageFromCBOR(signedStateCbor)
+	if err != nil {
+		return nil, err
+	}
+
+	err = VerifySignature(ctx, signedState, verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := massifs.NewRootSignerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeLogState(ctx, signedState, codec)
+}
@@ -0,0 +1,191 @@
+package merklelog
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-common/logger"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+/**
+ * Massif cache holds the massifs of a tenant's merklelog read so far,
+ *  so each massif is only read from blob storage once.
+ *
+ * The massif cache holds the massif height of the merklelog too, given explicitly, or else discovered
+ *  from the start header of the first massif when first needed. Only discovering the massif height
+ *  reads the first massif, an explicit massif height is validated against every massif read.
+ *
+ * A massif cache is safe for concurrent use, so a long lived verifier, e.g. the verification service, can share
+ *  one across all its requests. The newest massif still grows, so a cached massif is read again once it is asked
+ *  for a node it does not hold yet.
+ */
+
+// MassifCache reads, and caches, the massifs of the merklelog of a tenant.
+type MassifCache struct {
+	ctx          context.Context
+	massifReader massifs.MassifReader
+	tenantID     string
+
+	// mu guards the massif height and the massifs read so far
+	mu sync.Mutex
+
+	// massifHeight is the massif height of the merklelog, 0 until discovered if not given
+	massifHeight uint8
+
+	massifs map[uint64]*massifs.MassifContext
+}
+
+// NewMassifCache creates a MassifCache for the merklelog of the given tenant, with the given massif height,
+//
+//	or the massif height discovered from the first massif if the given massif height is 0.
+func NewMassifCache(ctx context.Context, reader azblob.Reader, tenantID string, massifHeight uint8) *MassifCache {
+
+	return &MassifCache{
+		ctx:          ctx,
+		massifReader: massifs.NewMassifReader(logger.Sugar, reader),
+		tenantID:     tenantID,
+		massifHeight: massifHeight,
+		massifs:      map[uint64]*massifs.MassifContext{},
+	}
+}
+
+// TenantID gets the tenant of the merklelog of the massifs
+func (mc *MassifCache) TenantID() string {
+	return mc.tenantID
+}
+
+// MassifHeight gets the massif height of the merklelog, discovering it from the start header
+//
+//	of the first massif the first time, if it was not given.
+func (mc *MassifCache) MassifHeight() (uint8, error) {
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.massifHeight != 0 {
+		return mc.massifHeight, nil
+	}
+
+	massif, err := mc.readMassif(0)
+	if err != nil {
+		return 0, err
+	}
+
+	mc.massifHeight = massif.Start.MassifHeight
+	mc.massifs[0] = massif
+
+	return mc.massifHeight, nil
+}
+
+// Massif gets the massif containing the given mmr index
+func (mc *MassifCache) Massif(mmrIndex uint64) (*massifs.MassifContext, error) {
+
+	massifHeight, err := mc.MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	massifIndex := massifs.MassifIndexFromMMRIndex(massifHeight, mmrIndex)
+
+	// the newest massif grows, so a cached massif not holding the mmr index yet is read again
+	mc.mu.Lock()
+	massifContext, ok := mc.massifs[massifIndex]
+	mc.mu.Unlock()
+	if ok && mmrIndex < massifContext.RangeCount() {
+		return massifContext, nil
+	}
+
+	massifContext, err = mc.readMassif(massifIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateMassifHeight(massifContext, massifHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	mc.mu.Lock()
+	mc.massifs[massifIndex] = massifContext
+	mc.mu.Unlock()
+
+	return massifContext, nil
+}
+
+// HeadMassif gets the most recent massif of the merklelog, it is not cached as it may still be growing.
+func (mc *MassifCache) HeadMassif() (*massifs.MassifContext, error) {
+
+	massifHeight, err := mc.MassifHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.StartSpan(mc.ctx, "massifs.GetHeadMassif", attribute.String(logging.KeyTenant, mc.tenantID))
+
+	start := time.Now()
+	massif, err := mc.massifReader.GetHeadMassif(ctx, mc.tenantID)
+	tracing.EndSpan(span, err)
+	metrics.BlobFetch(metrics.BlobMassif, start)
+	if err != nil {
+		slog.Debug("failed to fetch head massif", logging.KeyTenant, mc.tenantID, logging.KeyDuration, time.Since(start), logging.KeyError, err)
+		return nil, err
+	}
+
+	massifIndex := uint64(massif.Start.MassifIndex)
+	logging.BlobFetch(mc.tenantID, massifIndex, massifs.TenantMassifBlobPath(mc.tenantID, massifIndex), start, nil)
+
+	err = validateMassifHeight(&massif, massifHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &massif, nil
+}
+
+// Massifs gets the massifs read so far, in massif index order
+func (mc *MassifCache) Massifs() []*massifs.MassifContext {
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	massifIndices := make([]uint64, 0, len(mc.massifs))
+	for massifIndex := range mc.massifs {
+		massifIndices = append(massifIndices, massifIndex)
+	}
+
+	sort.Slice(massifIndices, func(i, j int) bool { return massifIndices[i] < massifIndices[j] })
+
+	massifsRead := make([]*massifs.MassifContext, 0, len(massifIndices))
+	for _, massifIndex := range massifIndices {
+		massifsRead = append(massifsRead, mc.massifs[massifIndex])
+	}
+
+	return massifsRead
+}
+
+// readMassif reads the given massif from blob storage, traced
+func (mc *MassifCache) readMassif(massifIndex uint64) (*massifs.MassifContext, error) {
+
+	ctx, span := tracing.StartSpan(mc.ctx, "massifs.GetMassif",
+		attribute.String(logging.KeyTenant, mc.tenantID), attribute.Int64(logging.KeyMassifIndex, int64(massifIndex)))
+
+	start := time.Now()
+	massif, err := mc.massifReader.GetMassif(ctx, mc.tenantID, massifIndex)
+	tracing.EndSpan(span, err)
+	metrics.BlobFetch(metrics.BlobMassif, start)
+	logging.BlobFetch(mc.tenantID, massifIndex, massifs.TenantMassifBlobPath(mc.tenantID, massifIndex), start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &massif, nil
+}
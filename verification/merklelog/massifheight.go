@@ -0,0 +1,38 @@
+package merklelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * Massif height is the height of the massifs of a tenant's merklelog, i.e. how many nodes each massif holds.
+ *
+ * All the index math placing an mmr index in a massif depends on the massif height, so a wrong massif height
+ *  silently reads the wrong massif. The massif height is discovered from the start header of the first
+ *  massif of the merklelog, or given explicitly, and every massif read is validated against it.
+ */
+
+var (
+	ErrMassifHeightMismatch = errors.New("massif height does not match the massif start header")
+)
+
+// DiscoverMassifHeight reads the massif height from the start header of the first massif of the tenant's merklelog
+func DiscoverMassifHeight(ctx context.Context, reader azblob.Reader, tenantID string) (uint8, error) {
+	return NewMassifCache(ctx, reader, tenantID, 0).MassifHeight()
+}
+
+// validateMassifHeight checks the given massif was read with the massif height of its start header
+func validateMassifHeight(massifContext *massifs.MassifContext, expectedHeight uint8) error {
+
+	if massifContext.Start.MassifHeight != expectedHeight {
+		return fmt.Errorf("%w: massif %d has height %d, expected %d",
+			ErrMassifHeightMismatch, massifContext.Start.MassifIndex, massifContext.Start.MassifHeight, expectedHeight)
+	}
+
+	return nil
+}
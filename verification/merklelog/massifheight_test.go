@@ -0,0 +1,134 @@
+package merklelog
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateMassifHeight tests massifs are only accepted at the massif height of their start header
+func TestValidateMassifHeight(t *testing.T) {
+
+	massifContext := &massifs.MassifContext{Start: massifs.MassifStart{MassifHeight: 14, MassifIndex: 2}}
+
+	assert.Equal(t, nil, validateMassifHeight(massifContext, 14))
+	assert.ErrorIs(t, validateMassifHeight(massifContext, 3), ErrMassifHeightMismatch)
+}
+
+// TestDiscoverMassifHeight tests the massif height of the synthetic log
+//
+//	is discovered from the start header of its first massif.
+func TestDiscoverMassifHeight(t *testing.T) {
+
+	blobs := newMassifBlobs(newSyntheticLog(t, "synthetic"))
+
+	discoveredHeight, err := DiscoverMassifHeight(context.Background(), blobs, syntheticTenantID)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint8(syntheticMassifHeight), discoveredHeight)
+	assert.Equal(t, map[uint64]bool{0: true}, blobs.massifsRead(t))
+}
+
+// TestMassifCache_MassifHeight tests the massif height is discovered once, from the first massif, when it is not given,
+//
+//	and that the first massif is not read when it is given.
+func TestMassifCache_MassifHeight(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	lastIndex := uint64(len(log.nodes) - 1)
+
+	t.Run("discovered", func(t *testing.T) {
+
+		blobs := newMassifBlobs(log)
+		massifCache := NewMassifCache(context.Background(), blobs, syntheticTenantID, 0)
+
+		_, err := massifCache.Massif(lastIndex)
+		require.NoError(t, err)
+
+		massifHeight, err := massifCache.MassifHeight()
+		require.NoError(t, err)
+
+		assert.Equal(t, uint8(syntheticMassifHeight), massifHeight)
+		assert.Equal(t, map[uint64]bool{0: true, 15: true}, blobs.massifsRead(t))
+	})
+
+	t.Run("given", func(t *testing.T) {
+
+		blobs := newMassifBlobs(log)
+		massifCache := NewMassifCache(context.Background(), blobs, syntheticTenantID, syntheticMassifHeight)
+
+		_, err := massifCache.Massif(lastIndex)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[uint64]bool{15: true}, blobs.massifsRead(t))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+
+		blobs := newMassifBlobs(log)
+		massifCache := NewMassifCache(context.Background(), blobs, syntheticTenantID, syntheticMassifHeight+1)
+
+		_, err := massifCache.Massif(0)
+		assert.ErrorIs(t, err, ErrMassifHeightMismatch)
+	})
+}
+
+// TestMassifCache_Growing tests a cached massif is read again once it is asked for a node it did not hold
+//
+//	when it was read, as the newest massif grows, and that the massifs held are not read again.
+func TestMassifCache_Growing(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	lastIndex := uint64(len(log.nodes) - 1)
+	headBlobPath := massifs.TenantMassifBlobPath(syntheticTenantID, 15)
+
+	// the head massif holds only the first of its two leaves when first read
+	partialLog := &syntheticLog{nodes: log.nodes[:mmr.TreeIndex(syntheticLeafCount-2)+1]}
+
+	blobs := newMassifBlobs(log)
+	blobs.blobs[headBlobPath] = partialLog.massifBlob(15)
+
+	massifCache := NewMassifCache(context.Background(), blobs, syntheticTenantID, syntheticMassifHeight)
+
+	_, err := massifCache.Massif(mmr.TreeIndex(syntheticLeafCount - 2))
+	require.NoError(t, err)
+
+	// the head massif grows to hold its second leaf
+	blobs.blobs[headBlobPath] = log.massifBlob(15)
+
+	_, err = massifCache.Massif(lastIndex)
+	require.NoError(t, err)
+
+	_, err = massifCache.Massif(mmr.TreeIndex(syntheticLeafCount - 2))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, blobs.reads[headBlobPath])
+}
+
+// TestMassifCache_Concurrent tests a massif cache shared by concurrent verifications reads every massif
+func TestMassifCache_Concurrent(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	blobs := newMassifBlobs(log)
+	massifCache := NewMassifCache(context.Background(), blobs, syntheticTenantID, 0)
+
+	var wg sync.WaitGroup
+	for leafIndex := uint64(0); leafIndex < syntheticLeafCount; leafIndex++ {
+
+		wg.Add(1)
+		go func(mmrIndex uint64) {
+			defer wg.Done()
+
+			_, err := massifCache.Massif(mmrIndex)
+			assert.NoError(t, err)
+		}(mmr.TreeIndex(leafIndex))
+	}
+	wg.Wait()
+
+	assert.Equal(t, 16, len(massifCache.Massifs()))
+}
@@ -0,0 +1,97 @@
+package merklelog
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+/**
+ * Massif store reads the nodes of a tenant's merklelog from whichever massif holds them.
+ *
+ * A single massif only holds its own nodes, and the peaks of the log before it in its peak stack.
+ *  A consistency proof between an old log state, e.g. in massif 0, and a new log state many massifs
+ *  later, needs nodes from the intermediate massifs too. The massif store loads each massif the
+ *  proof needs a node from, and only those massifs.
+ */
+
+var (
+	ErrLogStatesOutOfOrder = errors.New("the older log state must be non empty, and no larger than the newer log state")
+)
+
+// NodeStore gets the nodes of a merklelog by mmr index
+type NodeStore interface {
+	Get(mmrIndex uint64) ([]byte, error)
+}
+
+// ConsistencyVerifier verifies a newer log state is consistent with an older log state
+type ConsistencyVerifier func(logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error)
+
+// MassifStore gets the nodes of a tenant's merklelog, across massif boundaries
+type MassifStore struct {
+	massifCache *MassifCache
+}
+
+// NewMassifStore creates a MassifStore reading massifs through the given massif cache
+func NewMassifStore(massifCache *MassifCache) *MassifStore {
+	return &MassifStore{
+		massifCache: massifCache,
+	}
+}
+
+// Get the node at the given mmr index, from the massif that holds it
+func (ms *MassifStore) Get(mmrIndex uint64) ([]byte, error) {
+
+	massifContext, err := ms.massifCache.Massif(mmrIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return massifContext.Get(mmrIndex)
+}
+
+// VerifyLogConsistency verifies the newer log state B is consistent with the older log state A,
+//
+//	reading the nodes of the consistency proof from the given store.
+func VerifyLogConsistency(store NodeStore, logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error) {
+
+	if logStateA.MMRSize == 0 || logStateA.MMRSize > logStateB.MMRSize {
+		return false, fmt.Errorf("%w: %d, %d", ErrLogStatesOutOfOrder, logStateA.MMRSize, logStateB.MMRSize)
+	}
+
+	peaksA, err := mmr.PeakBagRHS(store, nil, 0, mmr.Peaks(logStateA.MMRSize))
+	if err != nil {
+		return false, err
+	}
+
+	proof, err := mmr.IndexConsistencyProof(logStateA.MMRSize, logStateB.MMRSize, store, sha256.New())
+	if err != nil {
+		return false, err
+	}
+
+	return mmr.VerifyConsistency(sha256.New(), peaksA, proof, logStateA.Root, logStateB.Root), nil
+}
+
+// NewConsistencyVerifier creates a ConsistencyVerifier reading the nodes of the consistency proofs from the given store,
+//
+//	tracing each verification as a child of any span of the given context.
+func NewConsistencyVerifier(ctx context.Context, store NodeStore) ConsistencyVerifier {
+
+	return func(logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error) {
+
+		_, span := tracing.StartSpan(ctx, "VerifyLogConsistency",
+			attribute.Int64("mmr_size_a", int64(logStateA.MMRSize)), attribute.Int64("mmr_size_b", int64(logStateB.MMRSize)))
+
+		consistent, err := VerifyLogConsistency(store, logStateA, logStateB)
+		span.SetAttributes(attribute.Bool("consistent", consistent))
+		tracing.EndSpan(span, err)
+
+		return consistent, err
+	}
+}
@@ -0,0 +1,292 @@
+package merklelog
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// syntheticTenantID is the tenant of the massif blobs of the synthetic log
+	syntheticTenantID = "tenant/00000000-0000-0000-0000-000000000000"
+
+	// syntheticMassifHeight is the massif height of the synthetic log, 2 leaves per massif
+	syntheticMassifHeight = 2
+
+	// syntheticLeafCount is the number of leaves in the synthetic log, spanning 16 massifs
+	syntheticLeafCount = 32
+
+	// layout of a massif blob: the start header, the index header, the trie of the leaves of the massif,
+	//  the fixed size stack of the peaks of the earlier massifs, then the nodes of the massif.
+	massifValueBytes       = 32
+	massifStartBytes       = 32
+	massifIndexHeaderBytes = 32
+	massifTrieEntryBytes   = 64
+	massifMaxPeakStackLen  = 64
+
+	// fields of the massif start header
+	massifStartVersionByte      = 21
+	massifStartEpochByte        = 23
+	massifStartMassifHeightByte = 27
+	massifStartMassifIndexByte  = 28
+)
+
+var (
+	errNotListed = errors.New("the massif blobs are only read by path")
+)
+
+// syntheticLog is an in memory merklelog, from which the massif blobs of a small massif height are written
+type syntheticLog struct {
+	nodes [][]byte
+}
+
+// newSyntheticLog creates a synthetic log with the given seed for its leaves
+func newSyntheticLog(t *testing.T, seed string) *syntheticLog {
+
+	log := &syntheticLog{}
+
+	for leafIndex := 0; leafIndex < syntheticLeafCount; leafIndex++ {
+
+		leaf := sha256.Sum256([]byte(fmt.Sprintf("%s leaf %d", seed, leafIndex)))
+
+		_, err := mmr.AddHashedLeaf(log, sha256.New(), leaf[:])
+		require.NoError(t, err)
+	}
+
+	return log
+}
+
+// Append a node to the synthetic log
+func (sl *syntheticLog) Append(value []byte) (uint64, error) {
+
+	sl.nodes = append(sl.nodes, value)
+
+	return uint64(len(sl.nodes)), nil
+}
+
+// Get the node at the given mmr index
+func (sl *syntheticLog) Get(mmrIndex uint64) ([]byte, error) {
+
+	if mmrIndex >= uint64(len(sl.nodes)) {
+		return nil, fmt.Errorf("mmr index %d is beyond the synthetic log", mmrIndex)
+	}
+
+	return sl.nodes[mmrIndex], nil
+}
+
+// logState gets the log state of the synthetic log after the given number of leaves
+func (sl *syntheticLog) logState(t *testing.T, leafCount uint64) *massifs.MMRState {
+
+	mmrSize := mmr.TreeIndex(leafCount)
+
+	root, err := mmr.GetRoot(mmrSize, sl, sha256.New())
+	require.NoError(t, err)
+
+	return &massifs.MMRState{MMRSize: mmrSize, Root: root}
+}
+
+// massifBlob writes the massif blob of the given massif of the synthetic log
+func (sl *syntheticLog) massifBlob(massifIndex uint64) []byte {
+
+	leavesPerMassif := uint64(1) << (syntheticMassifHeight - 1)
+
+	firstIndex := mmr.TreeIndex(massifIndex * leavesPerMassif)
+	endIndex := min(mmr.TreeIndex((massifIndex+1)*leavesPerMassif), uint64(len(sl.nodes)))
+
+	start := make([]byte, massifStartBytes)
+	binary.BigEndian.PutUint16(start[massifStartVersionByte:], 0)
+	binary.BigEndian.PutUint32(start[massifStartEpochByte:], 1)
+	start[massifStartMassifHeightByte] = syntheticMassifHeight
+	binary.BigEndian.PutUint32(start[massifStartMassifIndexByte:], uint32(massifIndex))
+
+	blob := append([]byte{}, start...)
+	blob = append(blob, make([]byte, massifIndexHeaderBytes+massifTrieEntryBytes*leavesPerMassif)...)
+
+	// the peaks of the log before the massif, which the nodes of the massif may be parents of
+	peakStack := make([]byte, massifMaxPeakStackLen*massifValueBytes)
+	for stackIndex, peak := range mmr.Peaks(firstIndex) {
+		copy(peakStack[stackIndex*massifValueBytes:], sl.nodes[peak-1])
+	}
+	blob = append(blob, peakStack...)
+
+	for _, node := range sl.nodes[firstIndex:endIndex] {
+		blob = append(blob, node...)
+	}
+
+	return blob
+}
+
+// massifBlobs is an in memory blob storage of the massif blobs of a synthetic log, counting the reads of each blob
+type massifBlobs struct {
+	blobs map[string][]byte
+
+	// mu guards the reads, as the massifs may be read concurrently
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+// newMassifBlobs creates the blob storage of the massif blobs of the given synthetic log, of the synthetic tenant
+func newMassifBlobs(log *syntheticLog) *massifBlobs {
+
+	blobs := &massifBlobs{blobs: map[string][]byte{}, reads: map[string]int{}}
+
+	massifCount := massifs.MassifIndexFromMMRIndex(syntheticMassifHeight, uint64(len(log.nodes)-1)) + 1
+	for massifIndex := uint64(0); massifIndex < massifCount; massifIndex++ {
+		blobs.blobs[massifs.TenantMassifBlobPath(syntheticTenantID, massifIndex)] = log.massifBlob(massifIndex)
+	}
+
+	return blobs
+}
+
+// Reads the massif blob of the given path
+func (mb *massifBlobs) Reads(ctx context.Context, identity string, opts ...azblob.Option) (*azblob.ReaderResponse, error) {
+
+	blob, ok := mb.blobs[identity]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, identity)
+	}
+
+	mb.mu.Lock()
+	mb.reads[identity]++
+	mb.mu.Unlock()
+
+	return &azblob.ReaderResponse{Reader: io.NopCloser(bytes.NewReader(blob)), ContentLength: int64(len(blob))}, nil
+}
+
+// FilteredList is not supported, the massif blobs are only read by path
+func (mb *massifBlobs) FilteredList(ctx context.Context, tagsFilter string, opts ...azblob.Option) (*azblob.FilterResponse, error) {
+	return nil, errNotListed
+}
+
+// List is not supported, the massif blobs are only read by path
+func (mb *massifBlobs) List(ctx context.Context, opts ...azblob.Option) (*azblob.ListerResponse, error) {
+	return nil, errNotListed
+}
+
+// massifsRead gets the indices of the massifs read, failing if any massif is read more than once
+func (mb *massifBlobs) massifsRead(t *testing.T) map[uint64]bool {
+
+	massifsRead := map[uint64]bool{}
+
+	for massifIndex := uint64(0); ; massifIndex++ {
+
+		blobPath := massifs.TenantMassifBlobPath(syntheticTenantID, massifIndex)
+		if _, ok := mb.blobs[blobPath]; !ok {
+			return massifsRead
+		}
+
+		assert.LessOrEqual(t, mb.reads[blobPath], 1, "massif %d is read more than once", massifIndex)
+		if mb.reads[blobPath] > 0 {
+			massifsRead[massifIndex] = true
+		}
+	}
+}
+
+// newSyntheticStore creates a massif store reading the massif blobs of the given synthetic log
+func newSyntheticStore(log *syntheticLog) (*MassifStore, *MassifCache, *massifBlobs) {
+
+	blobs := newMassifBlobs(log)
+	massifCache := NewMassifCache(context.Background(), blobs, syntheticTenantID, syntheticMassifHeight)
+
+	return NewMassifStore(massifCache), massifCache, blobs
+}
+
+// TestVerifyLogConsistency_CrossMassif tests consistency between a log state in massif 0,
+//
+//	and a log state many massifs later, reading nodes from the blobs of the intermediate massifs.
+func TestVerifyLogConsistency_CrossMassif(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	store, _, blobs := newSyntheticStore(log)
+
+	logStateA := log.logState(t, 2)
+	logStateB := log.logState(t, syntheticLeafCount)
+
+	assert.Equal(t, uint64(0), massifs.MassifIndexFromMMRIndex(syntheticMassifHeight, logStateA.MMRSize-1))
+	assert.Equal(t, uint64(15), massifs.MassifIndexFromMMRIndex(syntheticMassifHeight, logStateB.MMRSize-1))
+
+	verified, err := VerifyLogConsistency(store, logStateA, logStateB)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, verified)
+
+	// the proof needs nodes beyond the massifs of the two log states, each massif is read once
+	massifsRead := blobs.massifsRead(t)
+	assert.Equal(t, true, massifsRead[0])
+	assert.Greater(t, len(massifsRead), 2)
+}
+
+// TestMassifStore_PeakStack tests the root of the whole log is recomputed from the last massif alone,
+//
+//	reading the nodes of the earlier massifs from its peak stack.
+func TestMassifStore_PeakStack(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	_, massifCache, blobs := newSyntheticStore(log)
+
+	logState := log.logState(t, syntheticLeafCount)
+
+	massifContext, err := massifCache.Massif(logState.MMRSize - 1)
+	require.NoError(t, err)
+
+	root, err := mmr.GetRoot(logState.MMRSize, massifContext, sha256.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, logState.Root, root)
+	assert.Equal(t, map[uint64]bool{15: true}, blobs.massifsRead(t))
+}
+
+// TestVerifyLogConsistency_Inconsistent tests log states that are not consistent across massifs
+func TestVerifyLogConsistency_Inconsistent(t *testing.T) {
+
+	log := newSyntheticLog(t, "synthetic")
+	forkedLog := newSyntheticLog(t, "forked")
+
+	logStateB := log.logState(t, syntheticLeafCount)
+
+	t.Run("forked", func(t *testing.T) {
+
+		store, _, _ := newSyntheticStore(log)
+
+		// the older log state is of a different log, so its peaks are not in the newer log
+		verified, err := VerifyLogConsistency(store, forkedLog.logState(t, 2), logStateB)
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, false, verified)
+	})
+
+	t.Run("tampered root", func(t *testing.T) {
+
+		store, _, _ := newSyntheticStore(log)
+
+		tampered := *logStateB
+		tampered.Root = append([]byte{}, logStateB.Root...)
+		tampered.Root[0] ^= 0xff
+
+		verified, err := VerifyLogConsistency(store, log.logState(t, 2), &tampered)
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, false, verified)
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+
+		store, _, _ := newSyntheticStore(log)
+
+		_, err := VerifyLogConsistency(store, logStateB, log.logState(t, 2))
+
+		assert.ErrorIs(t, err, ErrLogStatesOutOfOrder)
+	})
+}
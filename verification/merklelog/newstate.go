@@ -0,0 +1,178 @@
+package merklelog
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-common/cose"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * New state selects the signed log state (seal) the existing signed log state is proven consistent with.
+ *
+ * By default the new state is the seal of the newest massif of the tenant's merklelog, so the consistency
+ *  demo always proves consistency up to now. A specific massif, or the massif holding a specific mmr index,
+ *  can be selected instead to prove consistency up to a point in the past.
+ *
+ * Where the blob storage can not be listed, e.g. from a browser, the newest seal is found by probing for seals instead.
+ */
+
+var (
+	ErrAmbiguousNewState = errors.New("select the new state by either mmr index or massif index, not both")
+	ErrNoSeal            = errors.New("no seal of the massif")
+)
+
+// NewStateSelector selects the massif whose seal is the new log state
+type NewStateSelector struct {
+
+	// MMRIndex selects the massif holding the mmr index, if set
+	MMRIndex *uint64
+
+	// MassifIndex selects the massif, if set
+	MassifIndex *uint64
+}
+
+// Latest is true if the newest massif of the merklelog is selected
+func (nss NewStateSelector) Latest() bool {
+	return nss.MMRIndex == nil && nss.MassifIndex == nil
+}
+
+// SelectedMassifIndex gets the index of the selected massif, listing the massifs of the merklelog
+//
+//	to find the newest massif if no massif is selected.
+func (nss NewStateSelector) SelectedMassifIndex(massifCache *MassifCache) (uint64, error) {
+
+	switch {
+	case nss.MMRIndex != nil && nss.MassifIndex != nil:
+		return 0, ErrAmbiguousNewState
+	case nss.MassifIndex != nil:
+		return *nss.MassifIndex, nil
+	case nss.MMRIndex != nil:
+
+		massifHeight, err := massifCache.MassifHeight()
+		if err != nil {
+			return 0, err
+		}
+
+		return massifs.MassifIndexFromMMRIndex(massifHeight, *nss.MMRIndex), nil
+	}
+
+	headMassif, err := massifCache.HeadMassif()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(headMassif.Start.MassifIndex), nil
+}
+
+// NewSignedState gets the seal of the selected massif of the merklelog of the tenant of the given massif cache,
+//
+//	finding the massif with the massif cache, so the massifs read are cached for verifying the seal too.
+//
+// The newest massif may not be sealed yet, so if the newest massif is selected and has no seal,
+// the seal of the massif before it is used. Any other failure to read the seal is returned as is.
+func NewSignedState(ctx context.Context, reader azblob.Reader, codec massifs.RootSignerCodec, massifCache *MassifCache, newState NewStateSelector) (*cose.CoseSign1Message, error) {
+
+	massifIndex, err := newState.SelectedMassifIndex(massifCache)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := massifCache.TenantID()
+
+	start := time.Now()
+	signedState, err := ReadSignedLogState(ctx, reader, sha256.New(), codec, tenantID, massifIndex)
+	metrics.BlobFetch(metrics.BlobSeal, start)
+	logging.BlobFetch(tenantID, massifIndex, massifs.TenantMassifSignedRootPath(tenantID, uint32(massifIndex)), start, err)
+	if err == nil || !newState.Latest() || massifIndex == 0 || !IsBlobNotFound(err) {
+		return signedState, err
+	}
+
+	start = time.Now()
+	previousSignedState, previousErr := ReadSignedLogState(ctx, reader, sha256.New(), codec, tenantID, massifIndex-1)
+	metrics.BlobFetch(metrics.BlobSeal, start)
+	logging.BlobFetch(tenantID, massifIndex-1, massifs.TenantMassifSignedRootPath(tenantID, uint32(massifIndex-1)), start, previousErr)
+	if previousErr != nil {
+		return nil, previousErr
+	}
+
+	return previousSignedState, nil
+}
+
+// NewestSealedMassif finds the newest massif with a seal, from the given sealed massif on, probing for seals
+//
+//	with the given function, for when the blob storage of the merklelog can not be listed.
+//
+// The seals of a merklelog are contiguous from its first massif, so the seals are probed ever further apart
+//
+//	until a massif without a seal is found, then the newest seal is searched for in between.
+func NewestSealedMassif(from uint64, sealed func(massifIndex uint64) (bool, error)) (uint64, error) {
+
+	ok, err := sealed(from)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrNoSeal, from)
+	}
+
+	newest, step := from, uint64(1)
+	for {
+
+		ok, err := sealed(newest + step)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+
+		newest += step
+		step *= 2
+	}
+
+	// newest is sealed, and newest + step is not, so the newest seal is in between
+	low, high := newest, newest+step
+	for high-low > 1 {
+
+		middle := low + (high-low)/2
+
+		ok, err := sealed(middle)
+		if err != nil {
+			return 0, err
+		}
+
+		if ok {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return low, nil
+}
+
+// SealExists is true if the given massif of the tenant's merklelog has a seal,
+//
+//	false only if the seal is not found, any other failure to read the seal is returned.
+func SealExists(ctx context.Context, reader azblob.Reader, tenantID string, massifIndex uint64) (bool, error) {
+
+	response, err := reader.Reads(ctx, massifs.TenantMassifSignedRootPath(tenantID, uint32(massifIndex)))
+	if IsBlobNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	response.Reader.Close()
+
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package merklelog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewStateSelector tests the massif selected for the new state, without reading the merklelog
+func TestNewStateSelector(t *testing.T) {
+
+	// the mmr index of an event in the first massif of the public tenant
+	mmrIndex := uint64(830)
+	massifIndex := uint64(3)
+
+	t.Run("mmr index", func(t *testing.T) {
+
+		newState := NewStateSelector{MMRIndex: &mmrIndex}
+
+		// the massif height is given, so no massif is read
+		selected, err := newState.SelectedMassifIndex(NewMassifCache(context.Background(), nil, config.TenantID, 14))
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(0), selected)
+		assert.Equal(t, false, newState.Latest())
+	})
+
+	t.Run("massif index", func(t *testing.T) {
+
+		newState := NewStateSelector{MassifIndex: &massifIndex}
+
+		selected, err := newState.SelectedMassifIndex(nil)
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, massifIndex, selected)
+		assert.Equal(t, false, newState.Latest())
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+
+		newState := NewStateSelector{MMRIndex: &mmrIndex, MassifIndex: &massifIndex}
+
+		_, err := newState.SelectedMassifIndex(nil)
+
+		assert.ErrorIs(t, err, ErrAmbiguousNewState)
+	})
+
+	t.Run("latest", func(t *testing.T) {
+		assert.Equal(t, true, NewStateSelector{}.Latest())
+	})
+}
+
+// TestNewestSealedMassif tests the newest seal is found by probing, without probing every massif
+func TestNewestSealedMassif(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		from     uint64
+		newest   uint64
+		expected uint64
+	}{
+		{name: "only the first massif is sealed", from: 0, newest: 0, expected: 0},
+		{name: "the next massif is the newest", from: 0, newest: 1, expected: 1},
+		{name: "many massifs later", from: 2, newest: 1000, expected: 1000},
+		{name: "newest seal is a probe", from: 0, newest: 15, expected: 15},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			probes := 0
+			newest, err := NewestSealedMassif(test.from, func(massifIndex uint64) (bool, error) {
+				probes++
+				return massifIndex <= test.newest, nil
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, newest)
+
+			// the probes double their distance, then halve it, so there are about two probes per bit of the newest massif index
+			assert.Less(t, probes, 25)
+		})
+	}
+
+	t.Run("from massif not sealed", func(t *testing.T) {
+
+		_, err := NewestSealedMassif(3, func(massifIndex uint64) (bool, error) {
+			return false, nil
+		})
+
+		assert.ErrorIs(t, err, ErrNoSeal)
+	})
+
+	t.Run("probe fails", func(t *testing.T) {
+
+		errProbe := errors.New("probe failed")
+
+		_, err := NewestSealedMassif(0, func(massifIndex uint64) (bool, error) {
+			if massifIndex > 4 {
+				return false, errProbe
+			}
+			return true, nil
+		})
+
+		assert.ErrorIs(t, err, errProbe)
+	})
+}
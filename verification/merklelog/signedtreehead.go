@@ -0,0 +1,219 @@
+package merklelog
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-common/cose"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/metrics"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+)
+
+/**
+ * Signed tree head verifies the merklelog_entry.confirm.signed_tree_head and the
+ *  merklelog_entry.unequivocal of events, when present.
+ *
+ * A signed tree head is a COSE Sign1 message, signed with the datatrails seal key, whose payload
+ *  is the state of the merklelog at the mmr size the event confirms. So the event itself carries
+ *  a self verifying commitment to the merklelog.
+ *
+ * The signed tree head is verified by:
+ *  1. verifying its signature with the datatrails seal verification key.
+ *  2. checking its log state matches the mmr size and root the event confirms.
+ *  3. cross checking its log state is consistent with the sealed log state of its massif.
+ */
+
+// TreeHeadStatus is the result of verifying a signed tree head of an event
+type TreeHeadStatus string
+
+const (
+	// TreeHeadAbsent the event has no signed tree head, so there is nothing to verify
+	TreeHeadAbsent TreeHeadStatus = "absent"
+
+	// TreeHeadVerified the signed tree head is signed by datatrails, matches the confirmed root,
+	//  and is consistent with the sealed log state.
+	TreeHeadVerified TreeHeadStatus = "verified"
+
+	// TreeHeadInvalid the signed tree head is not a COSE Sign1 message signed by datatrails
+	TreeHeadInvalid TreeHeadStatus = "invalid"
+
+	// TreeHeadInconsistent the signed tree head does not match the confirmed root,
+	//  or is not consistent with the sealed log state.
+	TreeHeadInconsistent TreeHeadStatus = "inconsistent"
+)
+
+// MerklelogConfirm is the merklelog_entry.confirm of an event, as returned by the datatrails events API
+type MerklelogConfirm struct {
+	MMRSize        string `json:"mmr_size"`
+	Root           []byte `json:"root"`
+	Timestamp      string `json:"timestamp"`
+	Idtimestamp    string `json:"idtimestamp"`
+	SignedTreeHead []byte `json:"signed_tree_head"`
+}
+
+// MerklelogUnequivocal is the merklelog_entry.unequivocal of an event, as returned by the datatrails events API
+type MerklelogUnequivocal struct {
+	MMRSize        string `json:"mmr_size"`
+	Root           []byte `json:"root"`
+	Timestamp      string `json:"timestamp"`
+	SignedTreeHead []byte `json:"signed_tree_head"`
+}
+
+// TreeHeadVerifier verifies the signed tree heads of events against the merklelog of a tenant.
+type TreeHeadVerifier struct {
+	ctx             context.Context
+	reader          azblob.Reader
+	tenantID        string
+	massifHeight    uint8
+	verificationKey *ecdsa.PublicKey
+	codec           massifs.RootSignerCodec
+
+	// sealedStates are the verified sealed log states read so far, keyed by massif index
+	sealedStates map[uint64]*massifs.MMRState
+}
+
+// NewTreeHeadVerifier creates a TreeHeadVerifier for the merklelog of the given tenant,
+//
+//	using the given datatrails seal verification key.
+func NewTreeHeadVerifier(ctx context.Context, reader azblob.Reader, tenantID string, massifHeight uint8, verificationKey *ecdsa.PublicKey) (*TreeHeadVerifier, error) {
+
+	codec, err := massifs.NewRootSignerCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TreeHeadVerifier{
+		ctx:             ctx,
+		reader:          reader,
+		tenantID:        tenantID,
+		massifHeight:    massifHeight,
+		verificationKey: verificationKey,
+		codec:           codec,
+		sealedStates:    map[uint64]*massifs.MMRState{},
+	}, nil
+}
+
+// VerifyTreeHeads verifies the signed tree head of the given confirmation, and of the given unequivocal
+//
+//	commitment if the event has one.
+func VerifyTreeHeads(verifier *TreeHeadVerifier, confirm MerklelogConfirm, unequivocal *MerklelogUnequivocal) (TreeHeadStatus, TreeHeadStatus, error) {
+
+	signedTreeHead, err := verifier.Verify(confirm.SignedTreeHead, confirm.MMRSize, confirm.Root)
+	if err != nil {
+		return "", "", err
+	}
+
+	if unequivocal == nil {
+		return signedTreeHead, TreeHeadAbsent, nil
+	}
+
+	unequivocalTreeHead, err := verifier.Verify(unequivocal.SignedTreeHead, unequivocal.MMRSize, unequivocal.Root)
+	if err != nil {
+		return "", "", err
+	}
+
+	return signedTreeHead, unequivocalTreeHead, nil
+}
+
+// Verify the given signed tree head commits to the given confirmed mmr size and root,
+//
+//	and is consistent with the sealed log state of the massif it is in.
+func (v *TreeHeadVerifier) Verify(signedTreeHead []byte, mmrSize string, root []byte) (TreeHeadStatus, error) {
+
+	if len(signedTreeHead) == 0 {
+		return TreeHeadAbsent, nil
+	}
+
+	treeHead, err := cose.NewCoseSign1MessageFromCBOR(signedTreeHead)
+	if err != nil {
+		return TreeHeadInvalid, nil
+	}
+
+	err = VerifySignature(v.ctx, treeHead, v.verificationKey)
+	if err != nil {
+		return TreeHeadInvalid, nil
+	}
+
+	treeHeadState, err := DecodeLogState(v.ctx, treeHead, v.codec)
+	if err != nil {
+		return TreeHeadInvalid, nil
+	}
+
+	// the signed tree head must commit to the same log state the event confirms
+	if strconv.FormatUint(treeHeadState.MMRSize, 10) != mmrSize || !bytes.Equal(treeHeadState.Root, root) {
+		return TreeHeadInconsistent, nil
+	}
+
+	if treeHeadState.MMRSize == 0 {
+		return TreeHeadInconsistent, nil
+	}
+
+	sealedState, err := v.SealedState(massifs.MassifIndexFromMMRIndex(v.massifHeight, treeHeadState.MMRSize-1))
+	if err != nil {
+		return "", err
+	}
+
+	// the older of the two log states must be consistent with the newer
+	oldState, newState := treeHeadState, sealedState
+	if sealedState.MMRSize < treeHeadState.MMRSize {
+		oldState, newState = sealedState, treeHeadState
+	}
+
+	if oldState.MMRSize == newState.MMRSize {
+		if !bytes.Equal(oldState.Root, newState.Root) {
+			return TreeHeadInconsistent, nil
+		}
+
+		return TreeHeadVerified, nil
+	}
+
+	consistent, err := VerifyConsistency(v.ctx, sha256.New(), v.reader, v.tenantID, oldState, newState)
+	if err != nil {
+		return "", err
+	}
+
+	if !consistent {
+		return TreeHeadInconsistent, nil
+	}
+
+	return TreeHeadVerified, nil
+}
+
+// SealedState gets the sealed log state of the given massif, verified with the datatrails seal verification key
+func (v *TreeHeadVerifier) SealedState(massifIndex uint64) (*massifs.MMRState, error) {
+
+	sealedState, ok := v.sealedStates[massifIndex]
+	if ok {
+		return sealedState, nil
+	}
+
+	start := time.Now()
+	signedState, err := ReadSignedLogState(v.ctx, v.reader, sha256.New(), v.codec, v.tenantID, massifIndex)
+	metrics.BlobFetch(metrics.BlobSeal, start)
+	logging.BlobFetch(v.tenantID, massifIndex, massifs.TenantMassifSignedRootPath(v.tenantID, uint32(massifIndex)), start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	err = VerifySignature(v.ctx, signedState, v.verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the seal of massif %d: %w", massifIndex, err)
+	}
+
+	sealedState, err = DecodeLogState(v.ctx, signedState, v.codec)
+	if err != nil {
+		return nil, err
+	}
+
+	v.sealedStates[massifIndex] = sealedState
+	metrics.LatestSeal(sealedState)
+
+	return sealedState, nil
+}
@@ -0,0 +1,72 @@
+package merklelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyTreeHeads_Unverifiable tests absent and malformed signed tree heads
+//
+//	are flagged without reading the merklelog.
+func TestVerifyTreeHeads_Unverifiable(t *testing.T) {
+
+	verificationKey, err := VerificationKeyFromFile(testVerificationKeyFile)
+	require.NoError(t, err)
+
+	verifier, err := NewTreeHeadVerifier(context.Background(), nil, config.DefaultTenantID, 14, verificationKey)
+	require.NoError(t, err)
+
+	confirm := MerklelogConfirm{
+		MMRSize: "512",
+		Root:    []byte("SnBhDOt7lF/aTK48db1qk0/86dluDr+y"),
+	}
+
+	tests := []struct {
+		name                string
+		signedTreeHead      []byte
+		unequivocal         *MerklelogUnequivocal
+		expectedTreeHead    TreeHeadStatus
+		expectedUnequivocal TreeHeadStatus
+	}{
+		{
+			name:                "absent",
+			expectedTreeHead:    TreeHeadAbsent,
+			expectedUnequivocal: TreeHeadAbsent,
+		},
+		{
+			name:                "unequivocal without signed tree head",
+			unequivocal:         &MerklelogUnequivocal{MMRSize: "512", Root: confirm.Root},
+			expectedTreeHead:    TreeHeadAbsent,
+			expectedUnequivocal: TreeHeadAbsent,
+		},
+		{
+			name:                "not cose",
+			signedTreeHead:      []byte("not a cose sign1 message"),
+			expectedTreeHead:    TreeHeadInvalid,
+			expectedUnequivocal: TreeHeadAbsent,
+		},
+		{
+			name:                "unequivocal not cose",
+			unequivocal:         &MerklelogUnequivocal{MMRSize: "512", Root: confirm.Root, SignedTreeHead: []byte{0xd2, 0x84}},
+			expectedTreeHead:    TreeHeadAbsent,
+			expectedUnequivocal: TreeHeadInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			confirm.SignedTreeHead = test.signedTreeHead
+
+			signedTreeHead, unequivocal, err := VerifyTreeHeads(verifier, confirm, test.unequivocal)
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.expectedTreeHead, signedTreeHead)
+			assert.Equal(t, test.expectedUnequivocal, unequivocal)
+		})
+	}
+}
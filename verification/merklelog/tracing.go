@@ -0,0 +1,104 @@
+package merklelog
+
+import (
+	"context"
+	"crypto"
+	"hash"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-common/cose"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/logging"
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+/**
+ * The traced verification steps, wrapping the calls into the datatrails libraries, so each is a span.
+ */
+
+// NewReader creates the merklelog reader of the configured blob storage, traced
+func NewReader(ctx context.Context) (azblob.Reader, error) {
+	return NewSettingsReader(ctx, config.Current())
+}
+
+// NewSettingsReader creates the merklelog reader of the blob storage of the given settings, e.g. of one profile, traced
+func NewSettingsReader(ctx context.Context, settings *config.Settings) (azblob.Reader, error) {
+
+	_, span := tracing.StartSpan(ctx, "azblob.NewReader",
+		attribute.String("url", settings.URL), attribute.String("container", settings.Container), attribute.Bool("authenticated", settings.AccountKey != ""))
+
+	reader, err := settings.NewBlobReader()
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// ReadSignedLogState reads the seal of the given massif of the tenant's merklelog, traced
+func ReadSignedLogState(ctx context.Context, reader azblob.Reader, hasher hash.Hash, codec massifs.RootSignerCodec, tenantID string, massifIndex uint64) (*cose.CoseSign1Message, error) {
+
+	ctx, span := tracing.StartSpan(ctx, "logverification.SignedLogState",
+		attribute.String(logging.KeyTenant, tenantID), attribute.Int64(logging.KeyMassifIndex, int64(massifIndex)))
+
+	signedState, err := logverification.SignedLogState(ctx, reader, hasher, codec, tenantID, massifIndex)
+	tracing.EndSpan(span, err)
+
+	return signedState, err
+}
+
+// VerifySignature verifies the COSE signature of the signed log state with the given key, traced
+func VerifySignature(ctx context.Context, signedState *cose.CoseSign1Message, verificationKey crypto.PublicKey) error {
+
+	_, span := tracing.StartSpan(ctx, "cose.VerifyWithPublicKey")
+
+	err := signedState.VerifyWithPublicKey(verificationKey, nil)
+	tracing.EndSpan(span, err)
+
+	return err
+}
+
+// DecodeLogState decodes the log state of the signed log state, traced
+func DecodeLogState(ctx context.Context, signedState *cose.CoseSign1Message, codec massifs.RootSignerCodec) (*massifs.MMRState, error) {
+
+	_, span := tracing.StartSpan(ctx, "logverification.LogState")
+
+	state, err := logverification.LogState(signedState, codec)
+	if err == nil {
+		span.SetAttributes(attribute.Int64(logging.KeyMMRSize, int64(state.MMRSize)))
+	}
+	tracing.EndSpan(span, err)
+
+	return state, err
+}
+
+// VerifyEventInclusion verifies the event is included on the tenant's merklelog, traced
+func VerifyEventInclusion(ctx context.Context, reader azblob.Reader, event logverification.VerifiableEvent, tenantID string, massifHeight uint8) (bool, error) {
+
+	_, span := tracing.StartSpan(ctx, "logverification.VerifyEvent",
+		attribute.String(logging.KeyTenant, tenantID), attribute.String(logging.KeyEventIdentity, event.EventID))
+
+	verified, err := logverification.VerifyEvent(reader, event, logverification.WithMassifTenantId(tenantID), logverification.WithMassifHeight(massifHeight))
+	span.SetAttributes(attribute.Bool("verified", verified))
+	tracing.EndSpan(span, err)
+
+	return verified, err
+}
+
+// VerifyConsistency verifies the newer log state of the tenant's merklelog is consistent with the older, traced
+func VerifyConsistency(ctx context.Context, hasher hash.Hash, reader azblob.Reader, tenantID string, logStateA *massifs.MMRState, logStateB *massifs.MMRState) (bool, error) {
+
+	ctx, span := tracing.StartSpan(ctx, "logverification.VerifyConsistency",
+		attribute.String(logging.KeyTenant, tenantID),
+		attribute.Int64("mmr_size_a", int64(logStateA.MMRSize)), attribute.Int64("mmr_size_b", int64(logStateB.MMRSize)))
+
+	consistent, err := logverification.VerifyConsistency(ctx, hasher, reader, tenantID, logStateA, logStateB)
+	span.SetAttributes(attribute.Bool("consistent", consistent))
+	tracing.EndSpan(span, err)
+
+	return consistent, err
+}
@@ -0,0 +1,60 @@
+package merklelog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/tracing"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testTracer records the spans of the verification steps in memory, for the duration of the test
+func testTracer(t *testing.T) *tracetest.InMemoryExporter {
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := tracing.Tracer
+	tracing.Tracer = tracerProvider.Tracer(tracing.Name)
+
+	t.Cleanup(func() {
+		tracing.Tracer = previous
+		tracerProvider.Shutdown(context.Background())
+	})
+
+	return exporter
+}
+
+// TestNewConsistencyVerifier_Span tests a failed consistency verification is a span, recording the error
+func TestNewConsistencyVerifier_Span(t *testing.T) {
+
+	exporter := testTracer(t)
+
+	ctx, parent := tracing.Tracer.Start(context.Background(), "consistency")
+
+	verifier := NewConsistencyVerifier(ctx, nil)
+
+	// the log states are out of order, so the store is never read
+	_, err := verifier(&massifs.MMRState{MMRSize: 7}, &massifs.MMRState{MMRSize: 3})
+	assert.ErrorIs(t, err, ErrLogStatesOutOfOrder)
+
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	span := spans[0]
+	assert.Equal(t, "VerifyLogConsistency", span.Name)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	assert.Equal(t, codes.Error, span.Status.Code)
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, "exception", span.Events[0].Name)
+	assert.Contains(t, span.Attributes, attribute.Int64("mmr_size_a", 7))
+	assert.Contains(t, span.Attributes, attribute.Bool("consistent", false))
+}
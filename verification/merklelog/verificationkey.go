@@ -0,0 +1,57 @@
+package merklelog
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+/**
+ * Verification key holds utilities for getting the datatrails public verification key from its pem.
+ *
+ * The demos read the pem from a file, the browser is given the pem by the caller, so either way
+ *  the key comes from wherever the caller trusts, not from the blob storage being verified.
+ */
+
+var (
+	ErrInvalidVerificationKey = errors.New("invalid verification key, expected a pem encoded ecdsa public key")
+)
+
+// VerificationKeyFromFile gets the datatrails public verification key, used
+//
+//	to verify the signature of merklelog seals, from the given pem file.
+func VerificationKeyFromFile(verificationKeyFile string) (*ecdsa.PublicKey, error) {
+
+	verificationKeyPem, err := os.ReadFile(verificationKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return VerificationKeyFromPEM(verificationKeyPem)
+}
+
+// VerificationKeyFromPEM gets the datatrails public verification key, used
+//
+//	to verify the signature of merklelog seals, from its pem.
+func VerificationKeyFromPEM(verificationKeyPem []byte) (*ecdsa.PublicKey, error) {
+
+	verificationKeyPemblock, _ := pem.Decode(verificationKeyPem)
+	if verificationKeyPemblock == nil {
+		return nil, fmt.Errorf("%w: no pem block", ErrInvalidVerificationKey)
+	}
+
+	parseResult, err := x509.ParsePKIXPublicKey(verificationKeyPemblock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVerificationKey, err)
+	}
+
+	verificationKey, ok := parseResult.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrInvalidVerificationKey, parseResult)
+	}
+
+	return verificationKey, nil
+}
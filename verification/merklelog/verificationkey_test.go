@@ -0,0 +1,49 @@
+package merklelog
+
+import (
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testVerificationKeyFile is the datatrails verification key, as in the demos and served to the demo page
+	testVerificationKeyFile = "testdata/verificationkey.pem"
+)
+
+// TestVerificationKeyFromFile tests the datatrails verification key is parsed from its pem file
+func TestVerificationKeyFromFile(t *testing.T) {
+
+	verificationKey, err := VerificationKeyFromFile(testVerificationKeyFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, elliptic.P384(), verificationKey.Curve)
+}
+
+// TestVerificationKeyFromPEM_Invalid tests anything but a pem encoded ecdsa public key is rejected
+func TestVerificationKeyFromPEM_Invalid(t *testing.T) {
+
+	tests := []struct {
+		name string
+		pem  string
+	}{
+		{name: "not pem", pem: "not a key"},
+		{name: "not a public key", pem: "-----BEGIN PUBLIC KEY-----\nbm90IGEga2V5\n-----END PUBLIC KEY-----\n"},
+		{
+			name: "not ecdsa",
+			pem: "-----BEGIN PUBLIC KEY-----\n" +
+				"MCowBQYDK2VwAyEAGb9ECWmEzf6FQbrBZ9w7lshQhqowtrbLDFw4rXAxZuE=\n" +
+				"-----END PUBLIC KEY-----\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, err := VerificationKeyFromPEM([]byte(test.pem))
+			assert.ErrorIs(t, err, ErrInvalidVerificationKey)
+		})
+	}
+}
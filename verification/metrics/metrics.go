@@ -0,0 +1,223 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+/**
+ * Metrics of the verifications, for observing the long running modes with prometheus.
+ *
+ * The metrics are:
+ *  1. counters of the verifications attempted, succeeded and failed, by type of verification.
+ *  2. a histogram of the latency of fetching blobs, massifs and seals, from blob storage.
+ *  3. gauges of the latest verified mmr size, and the age of the latest verified seal.
+ *
+ * The metrics of the process are always recorded, and exposed on /metrics when a metrics address is given.
+ */
+
+const (
+	// Path is the http path the metrics are served on
+	Path = "/metrics"
+
+	namespace = "datatrails_verification"
+
+	// readHeaderTimeout bounds reading the request headers of a scrape
+	readHeaderTimeout = 5 * time.Second
+
+	// BlobMassif and BlobSeal are the blob types of the blob fetch latency histogram
+	BlobMassif = "massif"
+	BlobSeal   = "seal"
+)
+
+var (
+	// Default is the metrics of the verifications of this process
+	Default = New()
+)
+
+// Metrics of the verifications, registered in their own prometheus registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	attempted *prometheus.CounterVec
+	succeeded *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+
+	blobFetchDuration *prometheus.HistogramVec
+
+	latestVerifiedMMRSize prometheus.Gauge
+
+	mu sync.Mutex
+
+	// latestMMRSize is the latest verified mmr size
+	latestMMRSize uint64
+
+	// latestSealTimestamp is the timestamp of the latest verified seal, in unix milliseconds
+	latestSealTimestamp int64
+}
+
+// New creates the verification metrics in a new registry
+func New() *Metrics {
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		attempted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "attempted_total",
+			Help:      "Verifications attempted, by type of verification.",
+		}, []string{"type"}),
+		succeeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "succeeded_total",
+			Help:      "Verifications that succeeded, by type of verification.",
+		}, []string{"type"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "failed_total",
+			Help:      "Verifications that failed, or could not complete, by type of verification.",
+		}, []string{"type"}),
+		blobFetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "blob_fetch_duration_seconds",
+			Help:      "Latency of fetching blobs from blob storage, by type of blob.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"blob"}),
+		latestVerifiedMMRSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "latest_verified_mmr_size",
+			Help:      "The mmr size of the latest verified log state.",
+		}),
+	}
+
+	sealAge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "latest_seal_age_seconds",
+		Help:      "The age of the latest verified seal, 0 until a seal is verified.",
+	}, m.sealAge)
+
+	m.registry.MustRegister(m.attempted, m.succeeded, m.failed, m.blobFetchDuration, m.latestVerifiedMMRSize, sealAge)
+
+	return m
+}
+
+// Verification records a verification of the given type, it failed if not verified, or if it errored
+func (m *Metrics) Verification(verificationType string, verified bool, err error) {
+
+	m.attempted.WithLabelValues(verificationType).Inc()
+
+	if err != nil || !verified {
+		m.failed.WithLabelValues(verificationType).Inc()
+		return
+	}
+
+	m.succeeded.WithLabelValues(verificationType).Inc()
+}
+
+// BlobFetch records the latency of a blob fetch of the given type of blob, started at the given time
+func (m *Metrics) BlobFetch(blob string, start time.Time) {
+	m.blobFetchDuration.WithLabelValues(blob).Observe(time.Since(start).Seconds())
+}
+
+// LatestVerified records the given verified mmr size, if it is the latest
+func (m *Metrics) LatestVerified(mmrSize uint64) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mmrSize < m.latestMMRSize {
+		return
+	}
+
+	m.latestMMRSize = mmrSize
+	m.latestVerifiedMMRSize.Set(float64(mmrSize))
+}
+
+// LatestSeal records the log state of the given verified seal, if it is the latest
+func (m *Metrics) LatestSeal(logState *massifs.MMRState) {
+
+	m.LatestVerified(logState.MMRSize)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if logState.Timestamp > m.latestSealTimestamp {
+		m.latestSealTimestamp = logState.Timestamp
+	}
+}
+
+// Handler gets the http handler serving the metrics in the prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve the metrics on the given address until the given context is done
+func (m *Metrics) Serve(ctx context.Context, address string) error {
+
+	mux := http.NewServeMux()
+	mux.Handle(Path, m.Handler())
+
+	server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// sealAge gets the age of the latest verified seal, in seconds
+func (m *Metrics) sealAge() float64 {
+
+	m.mu.Lock()
+	sealTimestamp := m.latestSealTimestamp
+	m.mu.Unlock()
+
+	if sealTimestamp == 0 {
+		return 0
+	}
+
+	return time.Since(time.UnixMilli(sealTimestamp)).Seconds()
+}
+
+// Verification records a verification of the given type on the metrics of this process
+func Verification(verificationType string, verified bool, err error) {
+	Default.Verification(verificationType, verified, err)
+}
+
+// BlobFetch records the latency of a blob fetch on the metrics of this process
+func BlobFetch(blob string, start time.Time) {
+	Default.BlobFetch(blob, start)
+}
+
+// LatestVerified records the given verified mmr size on the metrics of this process
+func LatestVerified(mmrSize uint64) {
+	Default.LatestVerified(mmrSize)
+}
+
+// LatestSeal records the log state of the given verified seal on the metrics of this process
+func LatestSeal(logState *massifs.MMRState) {
+	Default.LatestSeal(logState)
+}
+
+// Handler gets the http handler serving the metrics of this process
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// Serve the metrics of this process on the given address until the given context is done
+func Serve(ctx context.Context, address string) error {
+	return Default.Serve(ctx, address)
+}
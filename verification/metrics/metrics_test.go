@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetrics_Verification tests verifications are counted as attempted, and either succeeded or failed
+func TestMetrics_Verification(t *testing.T) {
+
+	m := New()
+
+	m.Verification("consistency", true, nil)
+	m.Verification("consistency", false, nil)
+	m.Verification("consistency", true, errors.New("blob storage unavailable"))
+	m.Verification("gossip", true, nil)
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.attempted.WithLabelValues("consistency")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.succeeded.WithLabelValues("consistency")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.failed.WithLabelValues("consistency")))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.attempted.WithLabelValues("gossip")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.failed.WithLabelValues("gossip")))
+}
+
+// TestMetrics_LatestSeal tests only the latest verified log state is recorded
+func TestMetrics_LatestSeal(t *testing.T) {
+
+	m := New()
+
+	assert.Equal(t, float64(0), m.sealAge())
+
+	sealTime := time.Now().Add(-time.Hour)
+
+	m.LatestSeal(&massifs.MMRState{MMRSize: 10, Timestamp: sealTime.UnixMilli()})
+
+	// an older seal, verified later, does not replace the latest
+	m.LatestSeal(&massifs.MMRState{MMRSize: 7, Timestamp: sealTime.Add(-time.Hour).UnixMilli()})
+
+	assert.Equal(t, float64(10), testutil.ToFloat64(m.latestVerifiedMMRSize))
+	assert.InDelta(t, time.Hour.Seconds(), m.sealAge(), 60)
+}
+
+// TestMetrics_Handler tests the metrics are served in the prometheus exposition format
+func TestMetrics_Handler(t *testing.T) {
+
+	m := New()
+
+	m.Verification("consistency", true, nil)
+	m.BlobFetch(BlobMassif, time.Now())
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	response, err := server.Client().Get(server.URL + Path)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `datatrails_verification_succeeded_total{type="consistency"} 1`)
+	assert.Contains(t, string(body), `datatrails_verification_blob_fetch_duration_seconds_count{blob="massif"} 1`)
+	assert.Contains(t, string(body), "datatrails_verification_latest_verified_mmr_size 0")
+	assert.Contains(t, string(body), "datatrails_verification_latest_seal_age_seconds 0")
+}
@@ -0,0 +1,121 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/**
+ * Tracing of the verification steps with OpenTelemetry, to tell whether the time of a slow verification
+ *  is spent reading blobs, verifying COSE signatures, or computing proofs.
+ *
+ * Spans are exported with OTLP over http, configured by the standard OTEL_EXPORTER_OTLP_* environment
+ *  variables, or printed to stdout for local runs. Nothing is exported by default.
+ */
+
+const (
+	// Name is the name of the tracer of the verification steps
+	Name = "github.com/datatrails/go-datatrails-demos"
+
+	// ExporterNone, ExporterStdout and ExporterOTLP are the exporters of the spans
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+var (
+	ErrUnknownExporter = errors.New("unknown trace exporter, expected one of stdout or otlp")
+)
+
+var (
+	// Tracer of the verification steps, it exports nothing until tracing is set up
+	Tracer = otel.Tracer(Name)
+
+	// shutdown ends the span of the run, then flushes the spans not yet exported, nil if not set up
+	shutdown func()
+)
+
+// Setup sets the global tracer provider, exporting the spans of the given service with the given exporter,
+//
+//	returns the context of the span of the whole run, which every verification step is a child of.
+func Setup(ctx context.Context, serviceName string, exporter string) (context.Context, error) {
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+
+	switch exporter {
+	case ExporterNone:
+		return ctx, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, exporter)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+
+	ctx, span := Tracer.Start(ctx, serviceName)
+
+	shutdown = func() {
+		span.End()
+		tracerProvider.Shutdown(context.Background())
+	}
+
+	return ctx, nil
+}
+
+// Flush ends the span of the run, then flushes the spans not yet exported
+func Flush() {
+
+	if shutdown == nil {
+		return
+	}
+
+	shutdown()
+	shutdown = nil
+}
+
+// Exit flushes the spans not yet exported, then exits with the given code
+func Exit(code int) {
+	Flush()
+	os.Exit(code)
+}
+
+// StartSpan starts a span of a verification step, as a child of any span of the given context
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends the span of a verification step, recording the error it failed with, if any
+func EndSpan(span trace.Span, err error) {
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetup tests nothing is exported by default, and unknown exporters are rejected
+func TestSetup(t *testing.T) {
+
+	ctx := context.Background()
+
+	tracingCtx, err := Setup(ctx, "consistency", ExporterNone)
+	require.NoError(t, err)
+	assert.Equal(t, ctx, tracingCtx)
+
+	// flushing without tracing set up is a no-op
+	Flush()
+
+	_, err = Setup(ctx, "consistency", "jaeger")
+	assert.ErrorIs(t, err, ErrUnknownExporter)
+}
@@ -0,0 +1,329 @@
+package witness
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/datatrails/go-datatrails-common/cose"
+	gocose "github.com/veraison/go-cose"
+)
+
+/**
+ * Witness quorum verifies a datatrails seal is co-signed by a quorum of independent witnesses,
+ *  as well as by datatrails, e.g. "signed by datatrails plus at least 2 of these 3 witnesses".
+ *
+ * The witness policy is a json file of the threshold and the witnesses, each witness has an id,
+ *  the key id of its co-signatures, and a pem encoded public key file, relative to the policy file:
+ *
+ *  {
+ *    "threshold": 2,
+ *    "witnesses": [
+ *      {"id": "witness-a.example.com", "public_key": "witness-a.pem"},
+ *      ...
+ *    ]
+ *  }
+ *
+ * A co-signature is a COSE Sign1 over exactly the same payload as the datatrails seal, the log state.
+ *  Co-signatures of other log states, or of witnesses not in the policy, do not count towards the quorum.
+ */
+
+var (
+	ErrUnsupportedKey         = errors.New("unsupported witness key, expected a P-256, P-384 or Ed25519 key")
+	ErrInvalidKeyPem          = errors.New("witness key file is not pem encoded")
+	ErrInvalidPolicy          = errors.New("invalid witness policy")
+	ErrQuorumNotMet           = errors.New("the seal is not co-signed by the quorum of witnesses")
+	ErrCoSignatureStateDiffer = errors.New("the co-signature is not over the log state of the seal")
+	ErrCoSignatureNoKeyID     = errors.New("the co-signature has no witness key id")
+)
+
+// PolicyWitness is a witness of the witness policy
+type PolicyWitness struct {
+
+	// ID is the key id of the witness co-signatures
+	ID string `json:"id"`
+
+	// PublicKeyFile is the pem file of the witness public key, relative to the policy file
+	PublicKeyFile string `json:"public_key"`
+
+	// PublicKey is the witness public key, read from the public key file
+	PublicKey crypto.PublicKey `json:"-"`
+}
+
+// Policy is the quorum of witnesses that must co-sign a seal
+type Policy struct {
+
+	// Threshold is the least number of witnesses that must co-sign the seal
+	Threshold int `json:"threshold"`
+
+	// Witnesses are the witnesses whose co-signatures count towards the threshold
+	Witnesses []PolicyWitness `json:"witnesses"`
+}
+
+// Quorum is the result of verifying the co-signatures of a seal against a witness policy
+type Quorum struct {
+
+	// Threshold is the least number of witnesses that must co-sign the seal
+	Threshold int
+
+	// Witnessed are the ids of the witnesses of the policy that co-signed the seal, in id order
+	Witnessed []string
+}
+
+// Met is true if at least the threshold of witnesses co-signed the seal
+func (wq Quorum) Met() bool {
+	return len(wq.Witnessed) >= wq.Threshold
+}
+
+// Err gets the error of a quorum that is not met, nil if the quorum is met
+func (wq Quorum) Err() error {
+
+	if wq.Met() {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d of the %d witnesses needed co-signed the seal", ErrQuorumNotMet, len(wq.Witnessed), wq.Threshold)
+}
+
+// ReadPolicy reads the witness policy file, and the public key file of each witness
+func ReadPolicy(policyPath string) (*Policy, error) {
+
+	policyJson, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+
+	err = json.Unmarshal(policyJson, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.Threshold < 1 || policy.Threshold > len(policy.Witnesses) {
+		return nil, fmt.Errorf("%w: threshold %d of %d witnesses", ErrInvalidPolicy, policy.Threshold, len(policy.Witnesses))
+	}
+
+	witnessIDs := map[string]bool{}
+	for index := range policy.Witnesses {
+
+		witness := &policy.Witnesses[index]
+
+		if witness.ID == "" || witnessIDs[witness.ID] {
+			return nil, fmt.Errorf("%w: witness ids must be unique and not empty, got %q", ErrInvalidPolicy, witness.ID)
+		}
+
+		witnessIDs[witness.ID] = true
+
+		publicKeyPath := witness.PublicKeyFile
+		if !filepath.IsAbs(publicKeyPath) {
+			publicKeyPath = filepath.Join(filepath.Dir(policyPath), publicKeyPath)
+		}
+
+		witness.PublicKey, err = PublicKeyFromFile(publicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// a witness listed twice under different ids would count twice towards the threshold
+		for _, other := range policy.Witnesses[:index] {
+			if samePublicKey(witness.PublicKey, other.PublicKey) {
+				return nil, fmt.Errorf("%w: witnesses %q and %q have the same public key", ErrInvalidPolicy, other.ID, witness.ID)
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// PublicKeyFromFile reads the pem encoded PKIX public key of a witness
+func PublicKeyFromFile(publicKeyPath string) (crypto.PublicKey, error) {
+
+	publicKeyPem, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyPemBlock, _ := pem.Decode(publicKeyPem)
+	if publicKeyPemBlock == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyPem, publicKeyPath)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(publicKeyPemBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = keyAlgorithm(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+}
+
+// samePublicKey is true if the given witness public keys are the same key
+func samePublicKey(publicKey crypto.PublicKey, other crypto.PublicKey) bool {
+
+	key, ok := publicKey.(interface{ Equal(crypto.PublicKey) bool })
+
+	return ok && key.Equal(other)
+}
+
+// ReadCoSignatures reads the co-signatures from the given files, or every file in the given directories
+func ReadCoSignatures(paths []string) ([][]byte, error) {
+
+	coSignatures := [][]byte{}
+	for _, path := range paths {
+
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		coSignaturePaths := []string{path}
+
+		if fileInfo.IsDir() {
+
+			dirEntries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+
+			coSignaturePaths = []string{}
+			for _, dirEntry := range dirEntries {
+				if dirEntry.Type().IsRegular() {
+					coSignaturePaths = append(coSignaturePaths, filepath.Join(path, dirEntry.Name()))
+				}
+			}
+		}
+
+		for _, coSignaturePath := range coSignaturePaths {
+
+			coSignature, err := os.ReadFile(coSignaturePath)
+			if err != nil {
+				return nil, err
+			}
+
+			coSignatures = append(coSignatures, coSignature)
+		}
+	}
+
+	return coSignatures, nil
+}
+
+// ReadQuorumFiles reads the witness policy, and the co-signatures in the given files or directories
+func ReadQuorumFiles(policyPath string, coSignaturePaths []string) (*Policy, [][]byte, error) {
+
+	policy, err := ReadPolicy(policyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coSignatures, err := ReadCoSignatures(coSignaturePaths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return policy, coSignatures, nil
+}
+
+// VerifyQuorum verifies which witnesses of the given policy co-signed the log state of the given datatrails seal.
+//
+// The datatrails signature of the seal must already be verified.
+func VerifyQuorum(policy *Policy, signedState *cose.CoseSign1Message, coSignatures [][]byte) Quorum {
+
+	witnessed := map[string]bool{}
+	for _, coSignature := range coSignatures {
+		for _, witness := range policy.Witnesses {
+
+			if witnessed[witness.ID] {
+				continue
+			}
+
+			// co-signatures of other log states, or by other keys, are not counted
+			witnessID, err := VerifyCoSignature(coSignature, signedState, witness.PublicKey)
+			if err != nil || witnessID != witness.ID {
+				continue
+			}
+
+			witnessed[witness.ID] = true
+		}
+	}
+
+	witnessQuorum := Quorum{Threshold: policy.Threshold, Witnessed: []string{}}
+	for witnessID := range witnessed {
+		witnessQuorum.Witnessed = append(witnessQuorum.Witnessed, witnessID)
+	}
+
+	sort.Strings(witnessQuorum.Witnessed)
+
+	return witnessQuorum
+}
+
+// VerifyCoSignature verifies the given co-signature is over the log state of the given datatrails seal,
+//
+//	and is signed by the given witness public key, returning the witness id of the co-signature.
+func VerifyCoSignature(coSignature []byte, signedState *cose.CoseSign1Message, publicKey crypto.PublicKey) (string, error) {
+
+	message := gocose.NewSign1Message()
+
+	err := message.UnmarshalCBOR(coSignature)
+	if err != nil {
+		return "", err
+	}
+
+	if !bytes.Equal(message.Payload, signedState.Payload) {
+		return "", ErrCoSignatureStateDiffer
+	}
+
+	keyID, ok := message.Headers.Protected[gocose.HeaderLabelKeyID].([]byte)
+	if !ok {
+		return "", ErrCoSignatureNoKeyID
+	}
+
+	algorithm, err := message.Headers.Protected.Algorithm()
+	if err != nil {
+		return "", err
+	}
+
+	verifier, err := gocose.NewVerifier(algorithm, publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	err = message.Verify(nil, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	return string(keyID), nil
+}
+
+// keyAlgorithm gets the COSE algorithm for the given witness public key
+func keyAlgorithm(publicKey crypto.PublicKey) (gocose.Algorithm, error) {
+
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return gocose.AlgorithmEd25519, nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return gocose.AlgorithmES256, nil
+		case elliptic.P384():
+			return gocose.AlgorithmES384, nil
+		}
+	}
+
+	return 0, ErrUnsupportedKey
+}
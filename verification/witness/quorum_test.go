@@ -0,0 +1,228 @@
+package witness
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPolicyWitnesses creates witnesses a, b and c, one of each supported algorithm,
+//
+//	writing their public keys to the given directory.
+func testPolicyWitnesses(t *testing.T, keyDir string) map[string]*Witness {
+
+	witnesses := map[string]*Witness{}
+
+	names := []string{"a", "b", "c"}
+	index := 0
+	for _, key := range testWitnessKeys(t) {
+
+		name := names[index]
+		index++
+
+		witness, err := New(name+".example.com", key)
+		require.NoError(t, err)
+
+		writePublicKey(t, filepath.Join(keyDir, name+".pem"), key.Public())
+
+		witnesses[name] = witness
+	}
+
+	return witnesses
+}
+
+// writePublicKey writes the pem encoded PKIX public key to the given file
+func writePublicKey(t *testing.T, publicKeyPath string, publicKey crypto.PublicKey) {
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(publicKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), filePerm))
+}
+
+// writePolicy writes the given witness policy json to a policy file in the given directory
+func writePolicy(t *testing.T, dir string, policyJson string) string {
+
+	policyPath := filepath.Join(dir, "policy.json")
+	require.NoError(t, os.WriteFile(policyPath, []byte(policyJson), filePerm))
+
+	return policyPath
+}
+
+const (
+	// testPolicyJson is a policy of at least 2 of the witnesses a, b and c
+	testPolicyJson = `{
+		"threshold": 2,
+		"witnesses": [
+			{"id": "a.example.com", "public_key": "a.pem"},
+			{"id": "b.example.com", "public_key": "b.pem"},
+			{"id": "c.example.com", "public_key": "c.pem"}
+		]
+	}`
+)
+
+// TestReadPolicy tests the witness public keys are read relative to the policy file
+func TestReadPolicy(t *testing.T) {
+
+	policyDir := t.TempDir()
+	testPolicyWitnesses(t, policyDir)
+
+	policy, err := ReadPolicy(writePolicy(t, policyDir, testPolicyJson))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, policy.Threshold)
+	require.Equal(t, 3, len(policy.Witnesses))
+
+	for _, witness := range policy.Witnesses {
+		assert.NotEqual(t, nil, witness.PublicKey)
+	}
+}
+
+// TestReadPolicy_Invalid tests policies that can never be met, or are ambiguous, or count a witness twice, are rejected
+func TestReadPolicy_Invalid(t *testing.T) {
+
+	policyDir := t.TempDir()
+	testPolicyWitnesses(t, policyDir)
+
+	tests := []struct {
+		name       string
+		policyJson string
+	}{
+		{
+			name:       "threshold above witnesses",
+			policyJson: `{"threshold": 2, "witnesses": [{"id": "a.example.com", "public_key": "a.pem"}]}`,
+		},
+		{
+			name:       "zero threshold",
+			policyJson: `{"threshold": 0, "witnesses": [{"id": "a.example.com", "public_key": "a.pem"}]}`,
+		},
+		{
+			name:       "duplicate witness",
+			policyJson: `{"threshold": 1, "witnesses": [{"id": "a.example.com", "public_key": "a.pem"}, {"id": "a.example.com", "public_key": "b.pem"}]}`,
+		},
+		{
+			name:       "duplicate public key",
+			policyJson: `{"threshold": 2, "witnesses": [{"id": "a.example.com", "public_key": "a.pem"}, {"id": "also-a.example.com", "public_key": "a.pem"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+
+			_, err := ReadPolicy(writePolicy(t, policyDir, test.policyJson))
+
+			assert.ErrorIs(t, err, ErrInvalidPolicy)
+		})
+	}
+
+	t.Run("unsupported key", func(t *testing.T) {
+
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		writePublicKey(t, filepath.Join(policyDir, "rsa.pem"), rsaKey.Public())
+
+		_, err = ReadPolicy(writePolicy(t, policyDir, `{"threshold": 1, "witnesses": [{"id": "rsa.example.com", "public_key": "rsa.pem"}]}`))
+
+		assert.ErrorIs(t, err, ErrUnsupportedKey)
+	})
+}
+
+// TestVerifyQuorum tests the quorum of witnesses that co-signed the log state of a seal
+func TestVerifyQuorum(t *testing.T) {
+
+	policyDir := t.TempDir()
+	witnesses := testPolicyWitnesses(t, policyDir)
+
+	policy, err := ReadPolicy(writePolicy(t, policyDir, testPolicyJson))
+	require.NoError(t, err)
+
+	signedState := testSignedState("log state")
+
+	coSign := func(witness *Witness, payload string) []byte {
+		coSignature, err := witness.CoSign(testSignedState(payload))
+		require.NoError(t, err)
+		return coSignature
+	}
+
+	t.Run("quorum met", func(t *testing.T) {
+
+		witnessQuorum := VerifyQuorum(policy, signedState, [][]byte{
+			coSign(witnesses["c"], "log state"),
+			coSign(witnesses["a"], "log state"),
+		})
+
+		assert.Equal(t, true, witnessQuorum.Met())
+		assert.Equal(t, nil, witnessQuorum.Err())
+		assert.Equal(t, []string{"a.example.com", "c.example.com"}, witnessQuorum.Witnessed)
+	})
+
+	t.Run("quorum not met", func(t *testing.T) {
+
+		witnessQuorum := VerifyQuorum(policy, signedState, [][]byte{
+			coSign(witnesses["a"], "log state"),
+
+			// the same witness only counts once
+			coSign(witnesses["a"], "log state"),
+
+			// co-signatures of other log states do not count
+			coSign(witnesses["b"], "another log state"),
+		})
+
+		assert.Equal(t, false, witnessQuorum.Met())
+		assert.ErrorIs(t, witnessQuorum.Err(), ErrQuorumNotMet)
+		assert.Equal(t, []string{"a.example.com"}, witnessQuorum.Witnessed)
+	})
+
+	t.Run("witness not in policy", func(t *testing.T) {
+
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		// a witness claiming the key id of a policy witness, without its key, does not count
+		impostor, err := New("b.example.com", otherKey)
+		require.NoError(t, err)
+
+		witnessQuorum := VerifyQuorum(policy, signedState, [][]byte{
+			coSign(witnesses["a"], "log state"),
+			coSign(impostor, "log state"),
+		})
+
+		assert.Equal(t, false, witnessQuorum.Met())
+		assert.Equal(t, []string{"a.example.com"}, witnessQuorum.Witnessed)
+	})
+}
+
+// TestReadCoSignatures tests co-signatures are read from files and directories
+func TestReadCoSignatures(t *testing.T) {
+
+	coSignatureDir := t.TempDir()
+
+	_, err := WriteCoSignature(coSignatureDir, []byte("co-signature 1"), 1)
+	require.NoError(t, err)
+
+	_, err = WriteCoSignature(coSignatureDir, []byte("co-signature 3"), 3)
+	require.NoError(t, err)
+
+	coSignatureFile := filepath.Join(t.TempDir(), "co-signature.cbor")
+	require.NoError(t, os.WriteFile(coSignatureFile, []byte("co-signature file"), filePerm))
+
+	coSignatures, err := ReadCoSignatures([]string{coSignatureFile, coSignatureDir})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]byte{
+		[]byte("co-signature file"),
+		[]byte("co-signature 1"),
+		[]byte("co-signature 3"),
+	}, coSignatures)
+}
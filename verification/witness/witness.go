@@ -0,0 +1,128 @@
+package witness
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/datatrails/go-datatrails-common/cose"
+	gocose "github.com/veraison/go-cose"
+)
+
+/**
+ * Witness counter-signs log states verified as consistent with our own witness key,
+ *  so our organisation acts as an independent witness of the datatrails merklelog.
+ *
+ * The co-signature is a COSE Sign1 over exactly the same payload as the datatrails seal,
+ *  the log state, so anyone with our public witness key can check we observed that log state.
+ *
+ * Witness keys are ES256 (P-256), ES384 (P-384) or Ed25519 private keys, in PEM encoded
+ *  PKCS8, or SEC1 for ecdsa keys.
+ */
+
+const (
+	// permissions of the directories and files of the co-signatures written
+	dirPerm  = 0755
+	filePerm = 0644
+)
+
+// Witness co-signs log states with a witness key
+type Witness struct {
+
+	// ID identifies the witness, it is the key id of the co-signatures
+	ID string
+
+	algorithm gocose.Algorithm
+	signer    gocose.Signer
+}
+
+// New creates a witness with the given id and private key,
+//
+//	the COSE algorithm is chosen by the type of the key.
+func New(id string, key crypto.Signer) (*Witness, error) {
+
+	algorithm, err := keyAlgorithm(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := gocose.NewSigner(algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Witness{ID: id, algorithm: algorithm, signer: signer}, nil
+}
+
+// FromFile creates a witness with the given id, and the private key in the given pem file
+func FromFile(id string, keyPath string) (*Witness, error) {
+
+	keyPem, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPemBlock, _ := pem.Decode(keyPem)
+	if keyPemBlock == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyPem, keyPath)
+	}
+
+	var key any
+
+	switch keyPemBlock.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(keyPemBlock.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(keyPemBlock.Bytes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	return New(id, signer)
+}
+
+// Algorithm is the COSE algorithm of the witness co-signatures
+func (w *Witness) Algorithm() gocose.Algorithm {
+	return w.algorithm
+}
+
+// CoSign counter-signs the log state of the given datatrails seal,
+//
+//	returning the CBOR encoded COSE Sign1 co-signature.
+func (w *Witness) CoSign(signedState *cose.CoseSign1Message) ([]byte, error) {
+
+	headers := gocose.Headers{
+		Protected: gocose.ProtectedHeader{
+			gocose.HeaderLabelAlgorithm: w.algorithm,
+			gocose.HeaderLabelKeyID:     []byte(w.ID),
+		},
+	}
+
+	return gocose.Sign1(rand.Reader, w.signer, headers, signedState.Payload, nil)
+}
+
+// WriteCoSignature writes the co-signature of the log state of the given mmr size to the given directory,
+//
+//	named by the mmr size, so the co-signatures are in log order.
+func WriteCoSignature(dir string, coSignature []byte, mmrSize uint64) (string, error) {
+
+	err := os.MkdirAll(dir, dirPerm)
+	if err != nil {
+		return "", err
+	}
+
+	coSignaturePath := filepath.Join(dir, fmt.Sprintf("%016d.cbor", mmrSize))
+
+	return coSignaturePath, os.WriteFile(coSignaturePath, coSignature, filePerm)
+}
@@ -0,0 +1,160 @@
+package witness
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-common/cose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gocose "github.com/veraison/go-cose"
+)
+
+// testSignedState is a datatrails seal with the given log state payload
+func testSignedState(payload string) *cose.CoseSign1Message {
+	return &cose.CoseSign1Message{Sign1Message: &gocose.Sign1Message{Payload: []byte(payload)}}
+}
+
+// testWitnessKeys are witness keys of every supported algorithm
+func testWitnessKeys(t *testing.T) map[gocose.Algorithm]crypto.Signer {
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return map[gocose.Algorithm]crypto.Signer{
+		gocose.AlgorithmES256:   p256Key,
+		gocose.AlgorithmES384:   p384Key,
+		gocose.AlgorithmEd25519: ed25519Key,
+	}
+}
+
+// TestWitness_CoSign tests the co-signature of each supported witness key verifies over the seal's log state
+func TestWitness_CoSign(t *testing.T) {
+
+	signedState := testSignedState("log state")
+
+	for algorithm, key := range testWitnessKeys(t) {
+		t.Run(algorithm.String(), func(t *testing.T) {
+
+			witness, err := New("example-witness", key)
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, witness.Algorithm())
+
+			coSignature, err := witness.CoSign(signedState)
+			require.NoError(t, err)
+
+			witnessID, err := VerifyCoSignature(coSignature, signedState, key.Public())
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "example-witness", witnessID)
+		})
+	}
+}
+
+// TestVerifyCoSignature_Invalid tests co-signatures over a different log state, or by a different key, fail
+func TestVerifyCoSignature_Invalid(t *testing.T) {
+
+	keys := testWitnessKeys(t)
+
+	witness, err := New("example-witness", keys[gocose.AlgorithmES256])
+	require.NoError(t, err)
+
+	coSignature, err := witness.CoSign(testSignedState("log state"))
+	require.NoError(t, err)
+
+	t.Run("different log state", func(t *testing.T) {
+
+		_, err := VerifyCoSignature(coSignature, testSignedState("another log state"), keys[gocose.AlgorithmES256].Public())
+
+		assert.ErrorIs(t, err, ErrCoSignatureStateDiffer)
+	})
+
+	t.Run("different key", func(t *testing.T) {
+
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = VerifyCoSignature(coSignature, testSignedState("log state"), otherKey.Public())
+
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+// TestNew_UnsupportedKey tests witness keys of unsupported curves are rejected
+func TestNew_UnsupportedKey(t *testing.T) {
+
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = New("example-witness", p521Key)
+
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+// TestFromFile tests witness keys are read from PKCS8 and SEC1 pem files
+func TestFromFile(t *testing.T) {
+
+	keys := testWitnessKeys(t)
+	keyDir := t.TempDir()
+
+	writeKey := func(name string, blockType string, der []byte) string {
+		keyPath := filepath.Join(keyDir, name)
+		require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), filePerm))
+		return keyPath
+	}
+
+	for algorithm, key := range keys {
+
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+
+		witness, err := FromFile("example-witness", writeKey(algorithm.String()+".pem", "PRIVATE KEY", der))
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, algorithm, witness.Algorithm())
+	}
+
+	sec1Der, err := x509.MarshalECPrivateKey(keys[gocose.AlgorithmES384].(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+
+	witness, err := FromFile("example-witness", writeKey("sec1.pem", "EC PRIVATE KEY", sec1Der))
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, gocose.AlgorithmES384, witness.Algorithm())
+
+	notPem := filepath.Join(keyDir, "not.pem")
+	require.NoError(t, os.WriteFile(notPem, []byte("not a pem file"), filePerm))
+
+	_, err = FromFile("example-witness", notPem)
+
+	assert.ErrorIs(t, err, ErrInvalidKeyPem)
+}
+
+// TestWriteCoSignature tests co-signatures are written named by the mmr size of their log state
+func TestWriteCoSignature(t *testing.T) {
+
+	witnessDir := filepath.Join(t.TempDir(), "witness")
+
+	coSignaturePath, err := WriteCoSignature(witnessDir, []byte("co-signature"), 831)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(witnessDir, "0000000000000831.cbor"), coSignaturePath)
+
+	coSignature, err := os.ReadFile(coSignaturePath)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("co-signature"), coSignature)
+}
@@ -0,0 +1,299 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-demos/wasm/verifier"
+)
+
+/**
+ * The JS API of the verifiers, set as the datatrails global of the page:
+ *
+ *  datatrails.verifyEvent({event, verificationKey, url, container, tenant, massifHeight})
+ *  datatrails.verifyConsistency({verificationKey, trustedSeal, massifIndex, url, container, tenant, massifHeight})
+ *
+ * Each returns a promise of the verdict, as the same json the verification service returns, or rejects
+ *  with an Error if the verification could not be done. Only the event, as json or an object, and the
+ *  verification key, as pem, are required, the merklelog is the public tenant's by default.
+ */
+
+var (
+	ErrMissingOption = errors.New("missing option")
+	ErrInvalidOption = errors.New("invalid option")
+	ErrPanic         = errors.New("verification failed unexpectedly")
+)
+
+// apiOptions are the options common to every function of the JS API
+type apiOptions struct {
+	url             string
+	container       string
+	verificationKey *ecdsa.PublicKey
+	verifierOptions verifier.Options
+}
+
+// verifyEvent is datatrails.verifyEvent, verifying the event of the given options
+func verifyEvent(this js.Value, args []js.Value) any {
+
+	return newPromise(func(ctx context.Context) (any, error) {
+
+		options, err := optionsArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		event, err := eventOption(options)
+		if err != nil {
+			return nil, err
+		}
+
+		eventVerifier, err := newVerifier(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return eventVerifier.VerifyEvent(ctx, event)
+	})
+}
+
+// verifyConsistency is datatrails.verifyConsistency, verifying the selected seal is consistent with the trusted seal
+func verifyConsistency(this js.Value, args []js.Value) any {
+
+	return newPromise(func(ctx context.Context) (any, error) {
+
+		options, err := optionsArg(args)
+		if err != nil {
+			return nil, err
+		}
+
+		trustedSeal, err := bytesOption(options, "trustedSeal")
+		if err != nil {
+			return nil, err
+		}
+
+		var massifIndex *uint64
+		if isSet(options.Get("massifIndex")) {
+
+			index, err := uintOption(options, "massifIndex", math.MaxUint32)
+			if err != nil {
+				return nil, err
+			}
+			massifIndex = &index
+		}
+
+		consistencyVerifier, err := newVerifier(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return consistencyVerifier.VerifyConsistency(ctx, trustedSeal, massifIndex)
+	})
+}
+
+// newVerifier creates a verifier of the merklelog of the given options, fetching its blobs with fetch()
+func newVerifier(ctx context.Context, options js.Value) (*verifier.Verifier, error) {
+
+	common, err := readOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := NewFetchReader(common.url, common.container)
+
+	return verifier.NewVerifier(ctx, reader, common.verificationKey, common.verifierOptions)
+}
+
+// readOptions reads the options common to every function of the JS API
+func readOptions(options js.Value) (apiOptions, error) {
+
+	common := apiOptions{}
+
+	verificationKeyPem, err := stringOption(options, "verificationKey", "")
+	if err != nil {
+		return apiOptions{}, err
+	}
+	if verificationKeyPem == "" {
+		return apiOptions{}, fmt.Errorf("%w: verificationKey", ErrMissingOption)
+	}
+
+	common.verificationKey, err = merklelog.VerificationKeyFromPEM([]byte(verificationKeyPem))
+	if err != nil {
+		return apiOptions{}, err
+	}
+
+	common.url, err = stringOption(options, "url", config.DefaultURL)
+	if err != nil {
+		return apiOptions{}, err
+	}
+
+	common.container, err = stringOption(options, "container", config.DefaultContainer)
+	if err != nil {
+		return apiOptions{}, err
+	}
+
+	common.verifierOptions.TenantID, err = stringOption(options, "tenant", config.DefaultTenantID)
+	if err != nil {
+		return apiOptions{}, err
+	}
+
+	massifHeight, err := uintOption(options, "massifHeight", 64)
+	if err != nil {
+		return apiOptions{}, err
+	}
+	common.verifierOptions.MassifHeight = uint8(massifHeight)
+
+	return common, nil
+}
+
+// optionsArg gets the options object, the only argument of every function of the JS API
+func optionsArg(args []js.Value) (js.Value, error) {
+
+	if len(args) != 1 || args[0].Type() != js.TypeObject {
+		return js.Undefined(), fmt.Errorf("%w: expected a single options object", ErrInvalidOption)
+	}
+
+	return args[0], nil
+}
+
+// eventOption gets the json of the event option, given either as json or as an object
+func eventOption(options js.Value) ([]byte, error) {
+
+	event := options.Get("event")
+
+	switch event.Type() {
+	case js.TypeString:
+		return []byte(event.String()), nil
+	case js.TypeObject:
+		return []byte(js.Global().Get("JSON").Call("stringify", event).String()), nil
+	case js.TypeUndefined, js.TypeNull:
+		return nil, fmt.Errorf("%w: event", ErrMissingOption)
+	default:
+		return nil, fmt.Errorf("%w: event must be json, or an object", ErrInvalidOption)
+	}
+}
+
+// stringOption gets the string option of the given name, the given default if it is not set
+func stringOption(options js.Value, name string, defaultValue string) (string, error) {
+
+	value := options.Get(name)
+	if !isSet(value) {
+		return defaultValue, nil
+	}
+
+	if value.Type() != js.TypeString {
+		return "", fmt.Errorf("%w: %s must be a string", ErrInvalidOption, name)
+	}
+
+	return value.String(), nil
+}
+
+// uintOption gets the whole number option of the given name, no greater than the given max, 0 if it is not set
+func uintOption(options js.Value, name string, maxValue uint64) (uint64, error) {
+
+	value := options.Get(name)
+	if !isSet(value) {
+		return 0, nil
+	}
+
+	if value.Type() != js.TypeNumber {
+		return 0, fmt.Errorf("%w: %s must be a number", ErrInvalidOption, name)
+	}
+
+	number := value.Float()
+	if number < 0 || number != math.Trunc(number) || number > float64(maxValue) {
+		return 0, fmt.Errorf("%w: %s must be a whole number from 0 to %d", ErrInvalidOption, name, maxValue)
+	}
+
+	return uint64(number), nil
+}
+
+// bytesOption gets the binary option of the given name, given either as a Uint8Array or as base64, nil if it is not set
+func bytesOption(options js.Value, name string) ([]byte, error) {
+
+	value := options.Get(name)
+	if !isSet(value) {
+		return nil, nil
+	}
+
+	if value.Type() == js.TypeString {
+
+		decoded, err := base64.StdEncoding.DecodeString(value.String())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be base64: %v", ErrInvalidOption, name, err)
+		}
+
+		return decoded, nil
+	}
+
+	if !value.InstanceOf(js.Global().Get("Uint8Array")) {
+		return nil, fmt.Errorf("%w: %s must be a Uint8Array, or base64", ErrInvalidOption, name)
+	}
+
+	decoded := make([]byte, value.Get("length").Int())
+	js.CopyBytesToGo(decoded, value)
+
+	return decoded, nil
+}
+
+// isSet is true if the given option is neither undefined nor null
+func isSet(value js.Value) bool {
+	return !value.IsUndefined() && !value.IsNull()
+}
+
+// newPromise creates a promise of the result of the given verification, run in a goroutine so the verification
+//
+//	can await the fetch() of blobs without blocking the event loop of the browser.
+//
+// The result is resolved as the object of its json, a failed verification is rejected as an Error.
+func newPromise(verify func(ctx context.Context) (any, error)) js.Value {
+
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) any {
+
+		resolve, reject := args[0], args[1]
+
+		go func() {
+			defer executor.Release()
+
+			// a panic would stop the go runtime, and with it every later verification of the page
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					reject.Invoke(newJSError(fmt.Errorf("%w: %v", ErrPanic, recovered)))
+				}
+			}()
+
+			result, err := verify(context.Background())
+			if err != nil {
+				reject.Invoke(newJSError(err))
+				return
+			}
+
+			resultJson, err := json.Marshal(result)
+			if err != nil {
+				reject.Invoke(newJSError(err))
+				return
+			}
+
+			resolve.Invoke(js.Global().Get("JSON").Call("parse", string(resultJson)))
+		}()
+
+		return nil
+	})
+
+	return js.Global().Get("Promise").New(executor)
+}
+
+// newJSError creates a JS Error of the given error
+func newJSError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
@@ -0,0 +1,155 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+)
+
+/**
+ * Fetch reader reads the blobs of the merklelog with the fetch() of the browser, straight from the
+ *  blob storage, so nothing between the browser and the blob storage is trusted.
+ *
+ * Each blob is read once, so every step of a verification sees the same bytes of a massif, or seal.
+ */
+
+var (
+	ErrFetch       = errors.New("failed to fetch blob")
+	ErrUnsupported = errors.New("unsupported in browser, the blob storage can not be listed, only read by path")
+)
+
+// FetchReader reads the blobs of a container of the blob storage with fetch(), caching each blob read.
+type FetchReader struct {
+	url       string
+	container string
+
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+// NewFetchReader creates a FetchReader of the given container of the blob storage at the given url
+func NewFetchReader(url string, container string) *FetchReader {
+	return &FetchReader{
+		url:       strings.TrimSuffix(url, "/"),
+		container: container,
+		blobs:     map[string][]byte{},
+	}
+}
+
+// Reads the blob of the given path, wrapping merklelog.ErrBlobNotFound if there is no such blob
+func (fr *FetchReader) Reads(ctx context.Context, identity string, opts ...azblob.Option) (*azblob.ReaderResponse, error) {
+
+	blob, err := fr.blob(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azblob.ReaderResponse{Reader: io.NopCloser(bytes.NewReader(blob))}, nil
+}
+
+// FilteredList is unsupported, the blob storage can not be listed from a browser
+func (fr *FetchReader) FilteredList(ctx context.Context, tagsFilter string, opts ...azblob.Option) (*azblob.FilterResponse, error) {
+	return nil, ErrUnsupported
+}
+
+// List is unsupported, the blob storage can not be listed from a browser, e.g. to find the head massif
+func (fr *FetchReader) List(ctx context.Context, opts ...azblob.Option) (*azblob.ListerResponse, error) {
+	return nil, ErrUnsupported
+}
+
+// blob gets the blob of the given path, fetching it unless it is read already
+func (fr *FetchReader) blob(ctx context.Context, identity string) ([]byte, error) {
+
+	fr.mu.Lock()
+	blob, ok := fr.blobs[identity]
+	fr.mu.Unlock()
+
+	if ok {
+		return blob, nil
+	}
+
+	blob, err := fetch(ctx, fr.url+"/"+fr.container+"/"+identity)
+	if err != nil {
+		return nil, err
+	}
+
+	fr.mu.Lock()
+	fr.blobs[identity] = blob
+	fr.mu.Unlock()
+
+	return blob, nil
+}
+
+// fetch gets the body of the given url with the fetch() of the browser, aborted once the given context is done
+func fetch(ctx context.Context, url string) ([]byte, error) {
+
+	controller := js.Global().Get("AbortController").New()
+	stop := context.AfterFunc(ctx, func() {
+		controller.Call("abort")
+	})
+	defer stop()
+
+	response, err := await(js.Global().Call("fetch", url, map[string]any{"signal": controller.Get("signal")}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrFetch, url, err)
+	}
+
+	status := response.Get("status").Int()
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", merklelog.ErrBlobNotFound, url)
+	}
+	if !response.Get("ok").Bool() {
+		return nil, fmt.Errorf("%w: %s: status %d", ErrFetch, url, status)
+	}
+
+	buffer, err := await(response.Call("arrayBuffer"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrFetch, url, err)
+	}
+
+	body := js.Global().Get("Uint8Array").New(buffer)
+	blob := make([]byte, body.Get("length").Int())
+	js.CopyBytesToGo(blob, body)
+
+	return blob, nil
+}
+
+// await waits for the given promise to settle, so it must not be called from the event loop of the browser,
+//
+//	only from a goroutine.
+func await(promise js.Value) (js.Value, error) {
+
+	resolved, rejected := make(chan js.Value, 1), make(chan js.Value, 1)
+
+	onResolved := js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolved <- args[0]
+		return nil
+	})
+	defer onResolved.Release()
+
+	onRejected := js.FuncOf(func(this js.Value, args []js.Value) any {
+		rejected <- args[0]
+		return nil
+	})
+	defer onRejected.Release()
+
+	promise.Call("then", onResolved, onRejected)
+
+	select {
+	case value := <-resolved:
+		return value, nil
+	case reason := <-rejected:
+		return js.Undefined(), errors.New(reason.Call("toString").String())
+	}
+}
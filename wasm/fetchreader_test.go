@@ -0,0 +1,97 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"io"
+	"syscall/js"
+	"testing"
+
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/**
+ * Tests of the fetch reader, run with node as the browser:
+ *
+ *  task gotest:go:wasm
+ */
+
+// fakeFetch replaces the fetch() of the page with one responding with the given blobs by url, 404 for any other url,
+//
+//	counting the fetches of each url.
+func fakeFetch(t *testing.T, blobs map[string]string) map[string]int {
+
+	fetches := map[string]int{}
+	promise := js.Global().Get("Promise")
+
+	fake := js.FuncOf(func(this js.Value, args []js.Value) any {
+
+		url := args[0].String()
+		fetches[url]++
+
+		blob, ok := blobs[url]
+		if !ok {
+			return promise.Call("resolve", map[string]any{"status": 404, "ok": false})
+		}
+
+		body := js.Global().Get("TextEncoder").New().Call("encode", blob).Get("buffer")
+		arrayBuffer := js.FuncOf(func(this js.Value, args []js.Value) any {
+			return promise.Call("resolve", body)
+		})
+		t.Cleanup(arrayBuffer.Release)
+
+		return promise.Call("resolve", map[string]any{"status": 200, "ok": true, "arrayBuffer": arrayBuffer})
+	})
+
+	original := js.Global().Get("fetch")
+	js.Global().Set("fetch", fake)
+	t.Cleanup(func() {
+		js.Global().Set("fetch", original)
+		fake.Release()
+	})
+
+	return fetches
+}
+
+// TestFetchReader_Reads tests blobs are fetched from the container of the blob storage, once each
+func TestFetchReader_Reads(t *testing.T) {
+
+	fetches := fakeFetch(t, map[string]string{
+		"https://blobs.example/merklelogs/v1/mmrs/massif.log": "massif",
+	})
+
+	reader := NewFetchReader("https://blobs.example/", "merklelogs")
+
+	for range 2 {
+
+		response, err := reader.Reads(context.Background(), "v1/mmrs/massif.log")
+		require.NoError(t, err)
+
+		blob, err := io.ReadAll(response.Reader)
+		require.NoError(t, err)
+		assert.Equal(t, "massif", string(blob))
+	}
+
+	assert.Equal(t, 1, fetches["https://blobs.example/merklelogs/v1/mmrs/massif.log"])
+
+	t.Run("blob not found", func(t *testing.T) {
+
+		_, err := reader.Reads(context.Background(), "v1/mmrs/seal.sth")
+		assert.ErrorIs(t, err, merklelog.ErrBlobNotFound)
+	})
+}
+
+// TestFetchReader_List tests listing the blob storage fails as unsupported, rather than panicking
+func TestFetchReader_List(t *testing.T) {
+
+	reader := NewFetchReader("https://blobs.example/", "merklelogs")
+
+	_, err := reader.List(context.Background())
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	_, err = reader.FilteredList(context.Background(), "lastid > '0'")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
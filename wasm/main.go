@@ -0,0 +1,33 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/datatrails/go-datatrails-common/logger"
+)
+
+// WebAssembly build of the inclusion and consistency verifiers, for customers to verify events in their browser
+//
+// Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o web/verify.wasm .
+//
+// The verifiers are set as the datatrails global of the page, which is sent the datatrails:ready
+// event once they can be called.
+func main() {
+
+	// the massif readers log to the datatrails logger, which is set up once here, discarding its logs
+	logger.New("NOOP")
+
+	js.Global().Set("datatrails", map[string]any{
+		"verifyEvent":       js.FuncOf(verifyEvent),
+		"verifyConsistency": js.FuncOf(verifyConsistency),
+	})
+
+	js.Global().Call("dispatchEvent", js.Global().Get("Event").New("datatrails:ready"))
+
+	// the verifiers are called by the page long after main would otherwise return
+	select {}
+}
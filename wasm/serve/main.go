@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"time"
+)
+
+/**
+ * Serve serves the demo page of the in-browser verifiers locally, and proxies the blob storage
+ *  of the merklelog on /verifiabledata, so the page can fetch() massifs and seals from a blob
+ *  storage that does not allow cross origin requests.
+ *
+ * The proxy is only a transport, every massif and seal fetched through it is verified in the
+ *  browser against the verification key given to the page, as if fetched from the blob storage.
+ */
+
+const (
+	// BlobProxyPath is the path the blob storage is proxied on
+	BlobProxyPath = "/verifiabledata"
+
+	defaultBlobURL = "https://app.datatrails.ai/verifiabledata"
+
+	readHeaderTimeout = 5 * time.Second
+)
+
+// Serves the demo page of the in-browser verifiers, built with task demos:wasm
+func main() {
+
+	listen := flag.String("listen", "localhost:8000", "address to serve the demo page on")
+	webDir := flag.String("web", "web", "directory of the demo page, with the verifiers built into it")
+	blobURL := flag.String("url", defaultBlobURL, "base url of the blob storage of the merklelog, proxied on "+BlobProxyPath)
+	flag.Parse()
+
+	target, err := url.Parse(*blobURL)
+	if err != nil {
+		fmt.Printf("Invalid blob storage url %q: %v\n", *blobURL, err)
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           NewHandler(*webDir, target),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	slog.Info("serving the demo page of the in-browser verifiers", "url", "http://"+*listen, "blob_url", target.String())
+
+	err = server.ListenAndServe()
+	if err != nil {
+		slog.Error("failed to serve the demo page", "error", err)
+		os.Exit(1)
+	}
+}
+
+// NewHandler creates the handler serving the demo page from the given directory,
+//
+//	and proxying the blob storage at the given url on BlobProxyPath.
+func NewHandler(webDir string, blobURL *url.URL) http.Handler {
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(blobURL)
+		},
+	}
+
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /", http.FileServer(http.Dir(webDir)))
+	mux.Handle("GET "+BlobProxyPath+"/", http.StripPrefix(BlobProxyPath, proxy))
+
+	return mux
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewHandler tests the demo page is served, and the blob storage is proxied read only
+func TestNewHandler(t *testing.T) {
+
+	blobStorage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("blob " + r.URL.Path))
+	}))
+	t.Cleanup(blobStorage.Close)
+
+	blobURL, err := url.Parse(blobStorage.URL + "/verifiabledata")
+	require.NoError(t, err)
+
+	webDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(webDir, "index.html"), []byte("demo page"), 0o600))
+
+	server := httptest.NewServer(NewHandler(webDir, blobURL))
+	t.Cleanup(server.Close)
+
+	get := func(path string) (int, string) {
+
+		response, err := server.Client().Get(server.URL + path)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		return response.StatusCode, string(body)
+	}
+
+	status, body := get("/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "demo page", body)
+
+	status, body = get(BlobProxyPath + "/merklelogs/v1/mmrs/massif.log")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "blob /verifiabledata/merklelogs/v1/mmrs/massif.log", body)
+
+	t.Run("proxy is read only", func(t *testing.T) {
+
+		response, err := server.Client().Post(server.URL+BlobProxyPath+"/merklelogs/v1/mmrs/massif.log", "text/plain", nil)
+		require.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	})
+}
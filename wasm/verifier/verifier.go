@@ -0,0 +1,261 @@
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/datatrails/go-datatrails-common/azblob"
+	"github.com/datatrails/go-datatrails-demos/verification/config"
+	"github.com/datatrails/go-datatrails-demos/verification/events"
+	"github.com/datatrails/go-datatrails-demos/verification/merklelog"
+	"github.com/datatrails/go-datatrails-logverification/logverification"
+	"github.com/datatrails/go-datatrails-merklelog/massifs"
+	"github.com/datatrails/go-datatrails-merklelog/mmr"
+)
+
+/**
+ * Verifier verifies datatrails events, and the consistency of the merklelog of a tenant, with nothing but
+ *  the blob storage of the merklelog and the datatrails seal verification key, so a customer can verify
+ *  in their browser without trusting any datatrails backend.
+ *
+ * An event is verified by:
+ *  1. verifying the event is included on the merklelog, as the inclusion demo does.
+ *  2. verifying the seal of the massif of the event with the verification key, and that the massif
+ *     commits to the sealed root, so the massif read is the massif datatrails sealed.
+ *  3. verifying the signed tree heads of the event, when present.
+ *
+ * A new log state is verified consistent with a trusted log state, as the consistency demo does. The
+ *  blob storage can not be listed from a browser, so the newest seal is found by probing for seals.
+ */
+
+// Options are the merklelog verified by a Verifier
+type Options struct {
+
+	// TenantID is the tenant of the merklelog, the public tenant if empty
+	TenantID string
+
+	// MassifHeight is the height of the massifs of the merklelog, discovered from the first massif if 0
+	MassifHeight uint8
+}
+
+// EventVerdict is the verdict of the verification of an event
+type EventVerdict struct {
+	Identity string `json:"identity"`
+	MMRIndex uint64 `json:"mmr_index"`
+
+	// Included is true if the event is included on the merklelog
+	Included bool `json:"included"`
+
+	// Sealed is true if the massif of the event is sealed by datatrails since the event was added,
+	//  and the massif commits to the sealed root.
+	Sealed bool `json:"sealed"`
+
+	// SignedTreeHead is the result of verifying the merklelog_entry.confirm.signed_tree_head
+	SignedTreeHead merklelog.TreeHeadStatus `json:"signed_tree_head,omitempty"`
+
+	// Unequivocal is the result of verifying the signed tree head of the merklelog_entry.unequivocal
+	Unequivocal merklelog.TreeHeadStatus `json:"unequivocal,omitempty"`
+
+	// Verified is true if the event is included and sealed, and none of its signed tree heads are invalid or inconsistent
+	Verified bool `json:"verified"`
+}
+
+// LogState is a log state of the merklelog, from a seal
+type LogState struct {
+	MMRSize uint64 `json:"mmr_size"`
+	Root    []byte `json:"root"`
+
+	// Timestamp of the seal, in unix milliseconds
+	Timestamp int64 `json:"timestamp"`
+}
+
+// ConsistencyVerdict is the verdict of the verification of a new log state against the trusted log state
+type ConsistencyVerdict struct {
+	Tenant string `json:"tenant"`
+
+	// MassifIndex is the massif of the seal of the new log state
+	MassifIndex uint64   `json:"massif_index"`
+	Trusted     LogState `json:"trusted"`
+	New         LogState `json:"new"`
+	Consistent  bool     `json:"consistent"`
+}
+
+// Verifier verifies events, and the consistency of the merklelog of a tenant, reading the merklelog with a reader.
+//
+// The massifs and seals read are cached, so a Verifier is meant for a single verification session.
+type Verifier struct {
+	reader          azblob.Reader
+	tenantID        string
+	massifHeight    uint8
+	verificationKey *ecdsa.PublicKey
+
+	massifStore *merklelog.MassifStore
+
+	// treeHeads verifies the signed tree heads, and the seals, read so far
+	treeHeads *merklelog.TreeHeadVerifier
+}
+
+// NewVerifier creates a Verifier of the merklelog of the given options, read with the given reader,
+//
+//	verifying seals with the given verification key.
+func NewVerifier(ctx context.Context, reader azblob.Reader, verificationKey *ecdsa.PublicKey, options Options) (*Verifier, error) {
+
+	tenantID := options.TenantID
+	if tenantID == "" {
+		tenantID = config.DefaultTenantID
+	}
+
+	massifHeight := options.MassifHeight
+	if massifHeight == 0 {
+
+		var err error
+		massifHeight, err = merklelog.DiscoverMassifHeight(ctx, reader, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover the massif height: %w", err)
+		}
+	}
+
+	treeHeads, err := merklelog.NewTreeHeadVerifier(ctx, reader, tenantID, massifHeight, verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Verifier{
+		reader:          reader,
+		tenantID:        tenantID,
+		massifHeight:    massifHeight,
+		verificationKey: verificationKey,
+		massifStore:     merklelog.NewMassifStore(merklelog.NewMassifCache(ctx, reader, tenantID, massifHeight)),
+		treeHeads:       treeHeads,
+	}, nil
+}
+
+// VerifyEvent verifies the given event, as json returned by the datatrails events API, is included
+//
+//	on the merklelog, sealed by datatrails, and verifies its signed tree heads.
+func (v *Verifier) VerifyEvent(ctx context.Context, eventJson []byte) (*EventVerdict, error) {
+
+	entry, err := events.NewEventEntry(eventJson)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiableEvent, err := logverification.NewVerifiableEvent(entry.EventJson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse event %s: %w", entry.Identity, err)
+	}
+
+	verdict := &EventVerdict{Identity: entry.Identity, MMRIndex: entry.MMRIndex()}
+
+	verdict.Included, err = merklelog.VerifyEventInclusion(ctx, v.reader, *verifiableEvent, v.tenantID, v.massifHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if !verdict.Included {
+		return verdict, nil
+	}
+
+	verdict.Sealed, err = v.verifySealed(ctx, entry.MMRIndex())
+	if err != nil {
+		return nil, err
+	}
+
+	verdict.SignedTreeHead, verdict.Unequivocal, err = merklelog.VerifyTreeHeads(v.treeHeads, entry.MerklelogEntry.Confirm, entry.MerklelogEntry.Unequivocal)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict.Verified = verdict.Sealed &&
+		verdict.SignedTreeHead != merklelog.TreeHeadInvalid && verdict.SignedTreeHead != merklelog.TreeHeadInconsistent &&
+		verdict.Unequivocal != merklelog.TreeHeadInvalid && verdict.Unequivocal != merklelog.TreeHeadInconsistent
+
+	return verdict, nil
+}
+
+// VerifyConsistency verifies the seal of the given massif, or else the newest seal, is consistent
+//
+//	with the given trusted seal, or else the sample seal of the public tenant.
+func (v *Verifier) VerifyConsistency(ctx context.Context, trustedSeal []byte, massifIndex *uint64) (*ConsistencyVerdict, error) {
+
+	if len(trustedSeal) == 0 {
+		trustedSeal = merklelog.SampleSignedStateCbor
+	}
+
+	trustedState, err := merklelog.VerifiedLogState(ctx, trustedSeal, v.verificationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the trusted seal: %w", err)
+	}
+
+	newMassifIndex := uint64(0)
+	if massifIndex != nil {
+		newMassifIndex = *massifIndex
+	} else {
+
+		// the newest seal is no older than the trusted seal
+		trustedMassifIndex := massifs.MassifIndexFromMMRIndex(v.massifHeight, max(trustedState.MMRSize, 1)-1)
+
+		newMassifIndex, err = merklelog.NewestSealedMassif(trustedMassifIndex, func(massifIndex uint64) (bool, error) {
+			return merklelog.SealExists(ctx, v.reader, v.tenantID, massifIndex)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newState, err := v.treeHeads.SealedState(newMassifIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	consistent, err := merklelog.VerifyLogConsistency(v.massifStore, trustedState, newState)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConsistencyVerdict{
+		Tenant:      v.tenantID,
+		MassifIndex: newMassifIndex,
+		Trusted:     newLogState(trustedState),
+		New:         newLogState(newState),
+		Consistent:  consistent,
+	}, nil
+}
+
+// verifySealed verifies the massif of the given mmr index is sealed since the mmr index was added,
+//
+//	and that the massif commits to the sealed root.
+func (v *Verifier) verifySealed(ctx context.Context, mmrIndex uint64) (bool, error) {
+
+	massifIndex := massifs.MassifIndexFromMMRIndex(v.massifHeight, mmrIndex)
+
+	exists, err := merklelog.SealExists(ctx, v.reader, v.tenantID, massifIndex)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	sealedState, err := v.treeHeads.SealedState(massifIndex)
+	if err != nil {
+		return false, err
+	}
+
+	// the mmr index was added after the massif was last sealed
+	if mmrIndex >= sealedState.MMRSize {
+		return false, nil
+	}
+
+	root, err := mmr.GetRoot(sealedState.MMRSize, v.massifStore, sha256.New())
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(root, sealedState.Root), nil
+}
+
+// newLogState gets the log state of the given mmr state
+func newLogState(mmrState *massifs.MMRState) LogState {
+	return LogState{MMRSize: mmrState.MMRSize, Root: mmrState.Root, Timestamp: mmrState.Timestamp}
+}
@@ -0,0 +1,104 @@
+// Loads the verifiers, built to verify.wasm, and calls them with the inputs of the demo page.
+
+const sampleEvent = {
+  identity: "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6/events/71d7ab65-359b-40d9-9bbd-102ec2092601",
+  asset_identity: "publicassets/3ea5aca3-da02-4bae-b6d0-85a5ab586ed6",
+  event_attributes: {
+    arc_description: "Approving Model",
+    arc_display_type: "Model Approval",
+    approvers: "Product Team",
+  },
+  asset_attributes: {
+    model_version: "mcbdc.01.0.0",
+    modelcard_version: "2.0.0",
+    datacard_version: "2.0.0",
+  },
+  operation: "Record",
+  behaviour: "RecordEvidence",
+  timestamp_declared: "2024-05-07T20:32:00Z",
+  timestamp_accepted: "2024-05-07T20:32:00Z",
+  timestamp_committed: "2024-05-07T20:32:27.235Z",
+  principal_declared: { issuer: "", subject: "", display_name: "", email: "" },
+  principal_accepted: { issuer: "", subject: "", display_name: "", email: "" },
+  confirmation_status: "CONFIRMED",
+  transaction_id: "0x224d41c6d984cb67e52274d62a48cd31fce39b6731f25f827d4c59a9cfdff427",
+  block_number: 7030,
+  transaction_index: 0,
+  from: "0x344b47d0FC35a551bd8a7Db4999226C04E764db3",
+  tenant_identity: "tenant/f023005c-000f-4a57-b2fe-eef425f243ad",
+  merklelog_entry: {
+    commit: { index: "499", idtimestamp: "018f54c1f0640dca00" },
+    confirm: {
+      mmr_size: "501",
+      root: "AsPmdY7mI1E4Hpkut1e1dYhj+gsRBS2c4NNLvZ0NMBg=",
+      timestamp: "1715113947353",
+      idtimestamp: "",
+      signed_tree_head: "",
+    },
+    unequivocal: null,
+  },
+};
+
+const element = (id) => document.getElementById(id);
+
+// options gets the options common to both verifiers from the inputs of the page
+function options() {
+  return {
+    verificationKey: element("verification-key").value,
+    url: new URL(element("url").value, location.href).href.replace(/\/$/, ""),
+    tenant: element("tenant").value,
+  };
+}
+
+// show shows the verdict of the given verification, or why it could not be done
+async function show(verification, isVerified) {
+  const verdict = element("verdict");
+  verdict.className = "";
+  verdict.textContent = "Verifying...";
+
+  try {
+    const result = await verification;
+    verdict.className = isVerified(result) ? "verified" : "failed";
+    verdict.textContent = JSON.stringify(result, null, 2);
+  } catch (err) {
+    verdict.className = "failed";
+    verdict.textContent = err.message;
+  }
+}
+
+element("sample-event").addEventListener("click", () => {
+  element("event").value = JSON.stringify(sampleEvent, null, 2);
+});
+
+element("verify-event").addEventListener("click", () => {
+  show(datatrails.verifyEvent({ ...options(), event: element("event").value }), (verdict) => verdict.verified);
+});
+
+element("verify-consistency").addEventListener("click", () => {
+  const trustedSeal = element("trusted-seal").value.trim();
+  show(
+    datatrails.verifyConsistency({ ...options(), trustedSeal: trustedSeal || undefined }),
+    (verdict) => verdict.consistent,
+  );
+});
+
+addEventListener("datatrails:ready", () => {
+  element("verify-event").disabled = false;
+  element("verify-consistency").disabled = false;
+  element("verdict").textContent = "Ready.";
+});
+
+// the verification key served with the page is only a convenience, replace it with one from a source you trust
+fetch("verificationkey.pem")
+  .then((response) => response.text())
+  .then((pem) => {
+    element("verification-key").value = pem;
+  });
+
+const go = new Go();
+WebAssembly.instantiateStreaming(fetch("verify.wasm"), go.importObject)
+  .then((result) => go.run(result.instance))
+  .catch((err) => {
+    element("verdict").className = "failed";
+    element("verdict").textContent = `Failed to load the verifiers, build them with task demos:wasm: ${err.message}`;
+  });